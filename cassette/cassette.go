@@ -0,0 +1,413 @@
+// Package cassette records http interactions to a file and replays them, so that tests written against a
+// real server can run deterministically without it.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Mode int
+
+const (
+	// ModeReplay serves recorded interactions and fails requests which were not recorded.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real transport and records every interaction.
+	ModeRecord
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	}
+	return fmt.Sprintf("mode(%d)", int(m))
+}
+
+func ParseMode(mode string) (Mode, error) {
+	switch strings.ToLower(mode) {
+	case "replay", "":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	}
+	return ModeReplay, fmt.Errorf("unknown cassette mode %q. expected 'record' or 'replay'", mode)
+}
+
+const (
+	scrubbed        = "[SCRUBBED]"
+	normalisedValue = "Thu, 01 Jan 1970 00:00:00 GMT"
+)
+
+var ErrUnmatchedRequest = errors.New("cassette has no recorded interaction matching the request")
+
+// DefaultScrubHeaders are replaced with a placeholder before an interaction is stored.
+var DefaultScrubHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "Signature"}
+
+// DefaultVolatileHeaders change on every request, they are normalised to a fixed value before an interaction
+// is stored.
+var DefaultVolatileHeaders = []string{"Date", "X-Request-Id", "Traceparent"}
+
+// DefaultScrubFields are json or form fields of request and response bodies, and query parameters, replaced with
+// a placeholder. Besides credentials they cover the account details the client redacts from its logs.
+var DefaultScrubFields = []string{"access_token", "refresh_token", "client_secret", "password", "iban",
+	"account_number", "name", "alternative_names", "customer_id"}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Cassette struct {
+	// Seed is picked at random on every recording, so that tests can generate new random values when recording
+	// and the recorded ones when replaying.
+	Seed         int64          `json:"seed"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper recording to, or replaying from, the cassette file at Path. Requests are
+// matched on method, path, query and body, ignoring the host, and each recorded interaction is replayed
+// once in the order it was recorded.
+type Recorder struct {
+	Path            string
+	Mode            Mode
+	Transport       http.RoundTripper
+	ScrubHeaders    []string
+	VolatileHeaders []string
+	ScrubFields     []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	replayed []bool
+}
+
+// New creates a recorder. In replay mode the cassette is loaded from path, in record mode requests are sent
+// with transport, or http.DefaultTransport when it is nil, and written to path by Save.
+func New(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		Path:            path,
+		Mode:            mode,
+		Transport:       transport,
+		ScrubHeaders:    DefaultScrubHeaders,
+		VolatileHeaders: DefaultVolatileHeaders,
+		ScrubFields:     DefaultScrubFields,
+	}
+
+	if mode == ModeReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading cassette %q. error: %w", path, err)
+		}
+
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("error decoding cassette %q. error: %w", path, err)
+		}
+		r.replayed = make([]bool, len(r.cassette.Interactions))
+	} else {
+		r.cassette.Seed = time.Now().UnixNano()
+	}
+
+	return r, nil
+}
+
+// Seed returns the seed of the cassette, a new one when recording and the recorded one when replaying.
+func (r *Recorder) Seed() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Seed
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body. error: %w", err)
+	}
+
+	recorded := Request{
+		Method: req.Method,
+		URL:    r.scrubURL(req.URL),
+		Header: r.clean(req.Header),
+		Body:   r.scrubBody(body),
+	}
+
+	if r.Mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	res, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := readBody(&res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body. error: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     r.clean(res.Header),
+			Body:       r.scrubBody(body),
+		},
+	})
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.replayed[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+		r.replayed[i] = true
+
+		res := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+			StatusCode:    res.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        res.Header.Clone(),
+			Body:          ioutil.NopCloser(strings.NewReader(res.Body)),
+			ContentLength: int64(len(res.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%s %s. %w", recorded.Method, recorded.URL, ErrUnmatchedRequest)
+}
+
+// Save writes the recorded interactions to Path. It does nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.Mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding cassette. error: %w", err)
+	}
+
+	if err := ioutil.WriteFile(r.Path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing cassette %q. error: %w", r.Path, err)
+	}
+	return nil
+}
+
+// Unplayed returns the recorded interactions which have not been replayed yet. It returns nothing in record mode.
+func (r *Recorder) Unplayed() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Mode != ModeReplay {
+		return nil
+	}
+
+	var unplayed []*Interaction
+	for i, interaction := range r.cassette.Interactions {
+		if !r.replayed[i] {
+			unplayed = append(unplayed, interaction)
+		}
+	}
+	return unplayed
+}
+
+func (r *Recorder) clean(header http.Header) http.Header {
+	cleaned := header.Clone()
+	for _, key := range r.ScrubHeaders {
+		if cleaned.Get(key) != "" {
+			cleaned.Set(key, scrubbed)
+		}
+	}
+
+	for _, key := range r.VolatileHeaders {
+		if cleaned.Get(key) != "" {
+			cleaned.Set(key, normalisedValue)
+		}
+	}
+	return cleaned
+}
+
+func (r *Recorder) scrubBody(body []byte) string {
+	if len(r.ScrubFields) == 0 || len(body) == 0 {
+		return string(body)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err == nil {
+		scrubJSON(document, r.ScrubFields)
+		if scrubbedBody, err := json.Marshal(document); err == nil {
+			return string(scrubbedBody)
+		}
+	}
+
+	if form, err := url.ParseQuery(string(body)); err == nil && strings.Contains(string(body), "=") {
+		changed := false
+		for _, field := range r.ScrubFields {
+			if form.Get(field) != "" {
+				form.Set(field, scrubbed)
+				changed = true
+			}
+		}
+		if changed {
+			return form.Encode()
+		}
+	}
+
+	return string(body)
+}
+
+// scrubURL replaces the query parameters named by ScrubFields, either directly or as a filter[field].
+func (r *Recorder) scrubURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for key := range query {
+		field := strings.TrimSuffix(strings.TrimPrefix(key, "filter["), "]")
+		if containsFold(r.ScrubFields, key) || containsFold(r.ScrubFields, field) {
+			query.Set(key, scrubbed)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return u.RequestURI()
+	}
+	scrubbedURL := *u
+	scrubbedURL.RawQuery = query.Encode()
+	return scrubbedURL.RequestURI()
+}
+
+func scrubJSON(document interface{}, fields []string) {
+	switch value := document.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if containsFold(fields, key) {
+				value[key] = scrubValue(child)
+				continue
+			}
+			scrubJSON(child, fields)
+		}
+	case []interface{}:
+		for _, child := range value {
+			scrubJSON(child, fields)
+		}
+	}
+}
+
+// scrubValue replaces every string of a field with the placeholder, keeping the shape of arrays and objects so
+// that the replayed body still decodes into the same types.
+func scrubValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		return scrubbed
+	case map[string]interface{}:
+		for key, child := range value {
+			value[key] = scrubValue(child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = scrubValue(child)
+		}
+	}
+	return value
+}
+
+func matches(recorded, req Request) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return false
+	}
+
+	if recordedURL.Path != reqURL.Path || !reflect.DeepEqual(recordedURL.Query(), reqURL.Query()) {
+		return false
+	}
+
+	return equalBodies(recorded.Body, req.Body)
+}
+
+// equalBodies compares json bodies by value, so that field order and whitespace do not matter.
+func equalBodies(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var documentA, documentB interface{}
+	if json.Unmarshal([]byte(a), &documentA) != nil || json.Unmarshal([]byte(b), &documentB) != nil {
+		return false
+	}
+	return reflect.DeepEqual(documentA, documentB)
+}
+
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+
+	*body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
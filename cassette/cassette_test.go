@@ -0,0 +1,184 @@
+package cassette
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-Request-Id", time.Now().String())
+		switch r.URL.Path {
+		case "/oauth2/token":
+			_, _ = w.Write([]byte(`{"access_token":"secret-token","token_type":"bearer","expires_in":3600}`))
+		default:
+			body, _ := ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(calls) + `,"echo":` + string(body) + `}`))
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "accounts.json")
+
+	recorder, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("failed to create recorder %q", err)
+	}
+	client := &http.Client{Transport: recorder}
+
+	token, _ := http.NewRequest("POST", server.URL+"/oauth2/token", strings.NewReader("grant_type=client_credentials&client_secret=hunter2"))
+	token.Header.Set("Authorization", "Basic c2VjcmV0")
+	send(t, client, token)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", server.URL+"/v1/organisation/accounts?b=2&a=1", strings.NewReader(`{"data":{"id":"1","type":"accounts"}}`))
+		req.Header.Set("Date", time.Now().Format(http.TimeFormat))
+		send(t, client, req)
+	}
+
+	if unplayed := recorder.Unplayed(); len(unplayed) != 0 {
+		t.Errorf("expected a recorder to have no unplayed interactions but got %v", unplayed)
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("failed to save cassette %q", err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	for _, secret := range []string{"hunter2", "secret-token", "c2VjcmV0"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains the secret %q", secret)
+		}
+	}
+	if !strings.Contains(string(data), `"Thu, 01 Jan 1970 00:00:00 GMT"`) {
+		t.Errorf("expected the volatile headers to be normalised\n%s", data)
+	}
+
+	replayer, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("failed to load cassette %q", err)
+	}
+	client = &http.Client{Transport: replayer}
+	recorded := calls
+
+	for _, expected := range []string{`"call":2`, `"call":3`} {
+		req, _ := http.NewRequest("POST", "http://replay.invalid/v1/organisation/accounts?a=1&b=2", strings.NewReader(`{"data":{"type":"accounts","id":"1"}}`))
+		if body := send(t, client, req); !strings.Contains(body, expected) {
+			t.Errorf("expected the replayed response to contain %q but got %q", expected, body)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "http://replay.invalid/v1/organisation/accounts?a=1&b=2", strings.NewReader(`{"data":{"type":"accounts","id":"1"}}`))
+	if _, err := client.Do(req); !errors.Is(err, ErrUnmatchedRequest) {
+		t.Errorf("expected a replayed interaction to be used only once but got %v", err)
+	}
+
+	req, _ = http.NewRequest("GET", "http://replay.invalid/v1/organisation/accounts/2", nil)
+	if _, err := client.Do(req); !errors.Is(err, ErrUnmatchedRequest) {
+		t.Errorf("expected an unmatched request to fail but got %v", err)
+	}
+
+	if calls != recorded {
+		t.Errorf("expected replay to never reach the server")
+	}
+
+	if unplayed := replayer.Unplayed(); len(unplayed) != 1 || unplayed[0].Request.URL != "/oauth2/token" {
+		t.Errorf("expected only the token request to be unplayed but got %v", unplayed)
+	}
+}
+
+func TestScrubAccountDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"attributes":{"iban":"GB33BUKB20201555555555","account_number":"55555555",` +
+			`"name":["Samantha Holder"],"alternative_names":null,"customer_id":"c-1","country":"GB"}}]}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "accounts.json")
+
+	recorder, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("failed to create recorder %q", err)
+	}
+	req, _ := http.NewRequest("GET", server.URL+"/v1/organisation/accounts?filter%5Biban%5D=GB33BUKB20201555555555", nil)
+	send(t, &http.Client{Transport: recorder}, req)
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("failed to save cassette %q", err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	for _, detail := range []string{"GB33BUKB20201555555555", "55555555", "Samantha Holder", "c-1"} {
+		if strings.Contains(string(data), detail) {
+			t.Errorf("cassette contains the account detail %q\n%s", detail, data)
+		}
+	}
+
+	replayer, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("failed to load cassette %q", err)
+	}
+	if replayer.Seed() != recorder.Seed() {
+		t.Errorf("expected the recorded seed %d to be replayed, got %d", recorder.Seed(), replayer.Seed())
+	}
+
+	req, _ = http.NewRequest("GET", "http://replay.invalid/v1/organisation/accounts?filter%5Biban%5D=GB00OTHER", nil)
+	body := send(t, &http.Client{Transport: replayer}, req)
+	if !strings.Contains(body, `"name":["[SCRUBBED]"]`) || !strings.Contains(body, `"country":"GB"`) {
+		t.Errorf("expected names to stay a list and other fields to be kept, got %s", body)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	modes := []struct {
+		mode     string
+		expected Mode
+		invalid  bool
+	}{
+		{"", ModeReplay, false},
+		{"replay", ModeReplay, false},
+		{"RECORD", ModeRecord, false},
+		{"rewind", ModeReplay, true},
+	}
+
+	for _, m := range modes {
+		mode, err := ParseMode(m.mode)
+		if mode != m.expected || (err != nil) != m.invalid {
+			t.Errorf("ParseMode(%q) returned %s, %v", m.mode, mode, err)
+		}
+	}
+
+	if _, err := New(filepath.Join(os.TempDir(), "missing-cassette.json"), ModeReplay, nil); err == nil {
+		t.Errorf("expected replaying a missing cassette to fail")
+	}
+}
+
+func send(t *testing.T, client *http.Client, req *http.Request) string {
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed with %q", err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	return string(body)
+}
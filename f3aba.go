@@ -0,0 +1,72 @@
+package form3
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// RoutingRule names the ABA routing number rule a US bank id failed.
+type RoutingRule string
+
+const (
+	RoutingFormat   RoutingRule = "routingFormat"
+	RoutingPrefix   RoutingRule = "routingPrefix"
+	RoutingChecksum RoutingRule = "routingChecksum"
+)
+
+var routingNumberRegex = regexp.MustCompile("^[0-9]{9}$")
+
+// abaWeights are applied to the digits of a routing number, the weighted sum is a multiple of 10.
+var abaWeights = [9]int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+
+type InvalidRoutingNumber struct {
+	routingNumber BankId
+	Rule          RoutingRule
+	reason        string
+}
+
+func (e *InvalidRoutingNumber) Error() string {
+	return fmt.Sprintf("invalid ABA routing number %q. %s", mask(string(e.routingNumber), 0, 4), e.reason)
+}
+
+func validateRoutingNumber(routingNumber BankId) error {
+	s := string(routingNumber)
+	if !routingNumberRegex.MatchString(s) {
+		return &InvalidRoutingNumber{routingNumber, RoutingFormat, "routing numbers are 9 digits"}
+	}
+
+	if prefix, _ := strconv.Atoi(s[:2]); !isFederalReservePrefix(prefix) {
+		return &InvalidRoutingNumber{routingNumber, RoutingPrefix, fmt.Sprintf(
+			"prefix %q is outside the Federal Reserve ranges 00-12, 21-32, 61-72 and 80", s[:2])}
+	}
+
+	total := 0
+	for i, weight := range abaWeights {
+		total += int(s[i]-'0') * weight
+	}
+	if total%10 != 0 {
+		return &InvalidRoutingNumber{routingNumber, RoutingChecksum, "3-7-1 check digit does not match"}
+	}
+
+	return nil
+}
+
+// isFederalReservePrefix accepts the government (00), primary (01-12), thrift (21-32), electronic (61-72) and
+// traveler's cheque (80) routing number prefixes.
+func isFederalReservePrefix(prefix int) bool {
+	return prefix <= 12 || (prefix >= 21 && prefix <= 32) || (prefix >= 61 && prefix <= 72) || prefix == 80
+}
+
+// abaRoutingValidator leaves bank ids rejected by the bankId length rule to that rule, so that a malformed
+// routing number is reported once.
+func abaRoutingValidator(ab createBuilder) (errors []error) {
+	if len(stringValidator(string(ab.BankId), bankIdValidationMap[ab.Country])) > 0 {
+		return errors
+	}
+
+	if err := validateRoutingNumber(ab.BankId); !ab.BankId.IsZeroValue() && err != nil {
+		errors = append(errors, err)
+	}
+	return errors
+}
@@ -0,0 +1,82 @@
+package form3
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRoutingNumberValidation(t *testing.T) {
+	routingNumbers := []struct {
+		scenario      string
+		routingNumber string
+		rule          RoutingRule
+	}{
+		{"Valid primary institution", "021000021", ""},
+		{"Valid Federal Reserve bank", "011000015", ""},
+		{"Valid routing number", "121000358", ""},
+		{"Valid thrift institution", "322271627", ""},
+		{"Too short", "02100002", RoutingFormat},
+		{"Not numeric", "02100002A", RoutingFormat},
+		{"Unassigned prefix", "130000006", RoutingPrefix},
+		{"Reserved prefix", "500000005", RoutingPrefix},
+		{"Invalid check digit", "021000022", RoutingChecksum},
+		{"Transposed digits", "012000021", RoutingChecksum},
+	}
+
+	for _, rn := range routingNumbers {
+		t.Run(rn.scenario, func(t *testing.T) {
+			err := validateRoutingNumber(BankId(rn.routingNumber))
+
+			if rn.rule == "" {
+				if err != nil {
+					t.Errorf("validation failed with %q on valid routing number %q", err, rn.routingNumber)
+				}
+				return
+			}
+
+			var invalidRoutingNumber *InvalidRoutingNumber
+			if !errors.As(err, &invalidRoutingNumber) {
+				t.Fatalf("expected an *InvalidRoutingNumber for %q, got %v", rn.routingNumber, err)
+			}
+			if invalidRoutingNumber.Rule != rn.rule {
+				t.Errorf("expected rule %q for %q, got %q: %v", rn.rule, rn.routingNumber, invalidRoutingNumber.Rule, err)
+			}
+			if strings.Contains(err.Error(), rn.routingNumber) {
+				t.Errorf("expected the routing number to be masked in %q", err)
+			}
+		})
+	}
+}
+
+func TestRoutingNumberOfWrongLengthIsReportedOnce(t *testing.T) {
+	accounts := []struct {
+		bankId BankId
+		rules  []string
+	}{
+		{"02100002", []string{"bankId"}},
+		{"02100002A", []string{"abaRouting"}},
+		{"021000022", []string{"abaRouting"}},
+	}
+
+	for _, a := range accounts {
+		t.Run(string(a.bankId), func(t *testing.T) {
+			errs := postValidators(createBuilder{
+				AccountId:      "81d62ace-23f2-4aff-a7d6-60d7674bc5bb",
+				OrganisationId: "ea68b98a-471a-4c71-ac83-0f96a2bee973",
+				AccountAttributes: AccountAttributes{
+					Country: "US", BankId: a.bankId, BankIdCode: "USABA", Bic: "CHASUS33",
+					AccountClassification: "Personal",
+				},
+			})
+
+			var rules []string
+			for _, err := range errs {
+				rules = append(rules, validationRule(err))
+			}
+			if strings.Join(rules, ",") != strings.Join(a.rules, ",") {
+				t.Errorf("expected failed rules %v but got %v: %v", a.rules, rules, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTokenExpiryDelta = 30 * time.Second
+	defaultTokenTimeout     = 30 * time.Second
+)
+
+// Authenticator authorises requests made to the api. Invalidate is called with a request which was rejected
+// with a 401, after which the request is retried once.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+	Invalidate(req *http.Request)
+}
+
+// ClientCredentials implements the OAuth2 client credentials flow. Tokens are cached until ExpiryDelta before
+// they expire, or until they are rejected when the token endpoint does not return an expiry, and concurrent
+// requests share a single in-flight token request which is abandoned after Timeout.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	HTTPClient   *http.Client
+	ExpiryDelta  time.Duration
+	Timeout      time.Duration
+	Logger       StructuredLogger
+
+	mu       sync.Mutex
+	token    string
+	expiry   time.Time
+	inflight *tokenCall
+}
+
+type tokenCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func NewClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) *ClientCredentials {
+	return &ClientCredentials{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+	}
+}
+
+func (cc *ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := cc.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (cc *ClientCredentials) Invalidate(req *http.Request) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if req.Header.Get("Authorization") == "Bearer "+cc.token {
+		cc.token = ""
+		cc.expiry = time.Time{}
+	}
+}
+
+func (cc *ClientCredentials) Token(ctx context.Context) (string, error) {
+	cc.mu.Lock()
+	if cc.token != "" && (cc.expiry.IsZero() || time.Now().Before(cc.expiry)) {
+		token := cc.token
+		cc.mu.Unlock()
+		return token, nil
+	}
+
+	call := cc.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		cc.inflight = call
+		go cc.refresh(call)
+	}
+	cc.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// refresh runs detached from the caller's context so that a cancelled caller does not fail the other
+// requests waiting on the same token, bounded by its own timeout so that a hung token endpoint does not block
+// every later caller.
+func (cc *ClientCredentials) refresh(call *tokenCall) {
+	timeout := cc.Timeout
+	if timeout <= 0 {
+		timeout = defaultTokenTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	res, err := cc.requestToken(ctx)
+
+	cc.mu.Lock()
+	if err == nil {
+		cc.token = res.AccessToken
+		cc.expiry = cc.tokenExpiry(res.ExpiresIn)
+		call.token = res.AccessToken
+	}
+	call.err = err
+	cc.inflight = nil
+	cc.mu.Unlock()
+
+	close(call.done)
+}
+
+// tokenExpiry returns when a token should be refreshed, or the zero time when the token endpoint returned no
+// expiry and the token is kept until it is rejected.
+func (cc *ClientCredentials) tokenExpiry(expiresIn int) time.Time {
+	if expiresIn <= 0 {
+		return time.Time{}
+	}
+
+	delta := cc.ExpiryDelta
+	if delta <= 0 {
+		delta = defaultTokenExpiryDelta
+	}
+
+	lifetime := time.Duration(expiresIn) * time.Second
+	if lifetime <= delta {
+		delta = lifetime / 2
+	}
+	return time.Now().Add(lifetime - delta)
+}
+
+func (cc *ClientCredentials) log() StructuredLogger {
+	if cc.Logger == nil {
+		return NewRedactingLogger(Logger)
+	}
+	return NewRedactingLogger(cc.Logger)
+}
+
+func (cc *ClientCredentials) requestToken(ctx context.Context) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(cc.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cc.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating token request Url: %q - error: %w", cc.TokenURL, err)
+	}
+	req.SetBasicAuth(url.QueryEscape(cc.ClientID), url.QueryEscape(cc.ClientSecret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := cc.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		cc.log().Log(LevelError, "error requesting access token", F("url", cc.TokenURL), F("error", err))
+		return nil, err
+	}
+	defer func() {
+		res.Body.Close()
+	}()
+
+	switch {
+	case res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnauthorized ||
+		res.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("token endpoint returned status code %d: %w", res.StatusCode, F3StatusForbidden)
+	case res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices:
+		return nil, fmt.Errorf("token endpoint returned status code %d", res.StatusCode)
+	}
+
+	token := &tokenResponse{}
+	if err := json.NewDecoder(res.Body).Decode(token); err != nil {
+		return nil, fmt.Errorf("error decoding token response. error: %w", err)
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned an empty access token")
+	}
+
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported token type %q", token.TokenType)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,200 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	issued    int32
+	expiresIn int
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	ts := &tokenServer{expiresIn: expiresIn}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" ||
+			r.PostForm.Get("scope") != "accounts:read accounts:write" {
+			t.Errorf("unexpected token request form %v", r.PostForm)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		n := atomic.AddInt32(&ts.issued, 1)
+		_ = json.NewEncoder(w).Encode(tokenResponse{
+			AccessToken: fmt.Sprintf("token-%d", n),
+			TokenType:   "Bearer",
+			ExpiresIn:   ts.expiresIn,
+		})
+	}))
+	return ts
+}
+
+func authenticatedServer(valid func(token string) bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); len(auth) < 7 || !valid(auth[7:]) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestClientCredentialsCachesToken(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	defer tokens.Close()
+
+	api := authenticatedServer(func(token string) bool { return token == "token-1" })
+	defer api.Close()
+
+	client := testClient(api, 0)
+	client.Authenticator = NewClientCredentials(tokens.URL, "client", "secret", "accounts:read", "accounts:write")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", api.URL, nil)
+			if err := client.request(req, nil); err != nil {
+				t.Errorf("authenticated request failed with %q", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if tokens.issued != 1 {
+		t.Errorf("expected a single token request for concurrent requests but %d were made", tokens.issued)
+	}
+}
+
+func TestClientCredentialsRefreshesBeforeExpiry(t *testing.T) {
+	tokens := newTokenServer(t, 1)
+	defer tokens.Close()
+
+	credentials := NewClientCredentials(tokens.URL, "client", "secret", "accounts:read", "accounts:write")
+	first, err := credentials.Token(context.Background())
+	if err != nil {
+		t.Fatalf("token request failed with %q", err)
+	}
+
+	time.Sleep(600 * time.Millisecond)
+	second, err := credentials.Token(context.Background())
+	if err != nil {
+		t.Fatalf("token request failed with %q", err)
+	}
+
+	if first == second {
+		t.Errorf("expected token %q to be refreshed before it expired", first)
+	}
+}
+
+func TestClientCredentialsRetriesUnauthorized(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	defer tokens.Close()
+
+	var calls int32
+	api := authenticatedServer(func(token string) bool {
+		atomic.AddInt32(&calls, 1)
+		return token == "token-2"
+	})
+	defer api.Close()
+
+	client := testClient(api, 0)
+	client.Authenticator = NewClientCredentials(tokens.URL, "client", "secret", "accounts:read", "accounts:write")
+
+	req, _ := http.NewRequest("GET", api.URL, nil)
+	if err := client.request(req, nil); err != nil {
+		t.Errorf("request should succeed with a fresh token, failed with %q", err)
+	}
+
+	api.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	req, _ = http.NewRequest("GET", api.URL, nil)
+	if err := client.request(req, nil); !errors.Is(err, F3StatusUnauthorized) {
+		t.Errorf("expected unauthorized error but got %v", err)
+	}
+
+	if calls != 4 || tokens.issued != 3 {
+		t.Errorf("expected a single retry per request, api received %d requests and %d tokens were issued", calls, tokens.issued)
+	}
+}
+
+func TestClientCredentialsInvalidCredentials(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	defer tokens.Close()
+
+	api := authenticatedServer(func(token string) bool { return true })
+	defer api.Close()
+
+	client := testClient(api, 0)
+	client.Authenticator = NewClientCredentials(tokens.URL, "client", "wrong")
+
+	req, _ := http.NewRequest("GET", api.URL, nil)
+	if err := client.request(req, nil); !errors.Is(err, F3StatusForbidden) {
+		t.Errorf("expected forbidden error for incorrect client credentials but got %v", err)
+	}
+}
+
+func TestClientCredentialsWithoutExpiry(t *testing.T) {
+	tokens := newTokenServer(t, 0)
+	defer tokens.Close()
+
+	credentials := NewClientCredentials(tokens.URL, "client", "secret", "accounts:read", "accounts:write")
+	for i := 0; i < 3; i++ {
+		if token, err := credentials.Token(context.Background()); err != nil || token != "token-1" {
+			t.Fatalf("expected the token without an expiry to be reused but got %q, %v", token, err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", tokens.URL, nil)
+	req.Header.Set("Authorization", "Bearer token-1")
+	credentials.Invalidate(req)
+	if token, err := credentials.Token(context.Background()); err != nil || token != "token-2" {
+		t.Errorf("expected a fresh token after invalidation but got %q, %v", token, err)
+	}
+}
+
+func TestClientCredentialsTokenTimeout(t *testing.T) {
+	release := make(chan struct{})
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer tokens.Close()
+	defer close(release)
+
+	var logs bytes.Buffer
+	credentials := NewClientCredentials(tokens.URL, "client", "secret")
+	credentials.Timeout = 20 * time.Millisecond
+	if _, err := New(WithBaseURL(tokens.URL), WithAuth(credentials),
+		WithLogger(NewStdLogger(log.New(&logs, "", 0), LevelDebug))); err != nil {
+		t.Fatalf("failed to create client %q", err)
+	}
+
+	if _, err := credentials.Token(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the token request to time out but got %v", err)
+	}
+	if !strings.Contains(logs.String(), "error requesting access token") {
+		t.Errorf("expected the token error to be logged to the client logger, got %q", logs.String())
+	}
+}
@@ -0,0 +1,221 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open. the account api is failing, requests are rejected until it recovers")
+
+type CircuitState int
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// CircuitBreakerSettings configures when the breaker trips. Either trigger can be disabled by leaving it at
+// its zero value.
+type CircuitBreakerSettings struct {
+	ConsecutiveFailures int
+	ErrorRate           float64
+	MinRequests         int
+	Window              time.Duration
+	OpenTimeout         time.Duration
+	HalfOpenRequests    int
+	OnStateChange       func(from, to CircuitState)
+}
+
+type CircuitBreaker struct {
+	mu                sync.Mutex
+	settings          CircuitBreakerSettings
+	state             CircuitState
+	consecutive       int
+	requests          int
+	failures          int
+	windowStart       time.Time
+	openedAt          time.Time
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+	generation        int
+}
+
+// admission identifies a request let through by allow, so that its outcome is only counted against the state
+// it was admitted in and only half-open probes free a probe slot.
+type admission struct {
+	generation int
+	probe      bool
+}
+
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.Window <= 0 {
+		settings.Window = time.Minute
+	}
+
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+
+	if settings.HalfOpenRequests < 1 {
+		settings.HalfOpenRequests = 1
+	}
+
+	return &CircuitBreaker{
+		settings:    settings,
+		windowStart: time.Now(),
+	}
+}
+
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.settings.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+func (b *CircuitBreaker) allow() (admission, error) {
+	b.mu.Lock()
+	from := b.state
+	a, err := b.admit()
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return a, err
+}
+
+func (b *CircuitBreaker) admit() (admission, error) {
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < b.settings.OpenTimeout {
+			return admission{}, ErrCircuitOpen
+		}
+		b.transition(CircuitHalfOpen)
+	}
+
+	a := admission{generation: b.generation}
+	if b.state == CircuitHalfOpen {
+		if b.halfOpenInFlight+b.halfOpenSuccesses >= b.settings.HalfOpenRequests {
+			return admission{}, ErrCircuitOpen
+		}
+		b.halfOpenInFlight++
+		a.probe = true
+	}
+
+	return a, nil
+}
+
+// done records the outcome of a request let through by allow. Only failures that indicate the api is
+// unhealthy count against the breaker, client errors such as a 404 or 409 and rate limited requests are treated
+// as successes. Requests admitted before the breaker last changed state are ignored.
+func (b *CircuitBreaker) done(ctx context.Context, a admission, err error) {
+	b.mu.Lock()
+	from := b.state
+
+	if a.generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	if a.probe && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+	case err != nil && !errors.Is(err, F3StatusTooManyRequests) && isRetryable(ctx, err):
+		b.onFailure()
+	default:
+		b.onSuccess()
+	}
+
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *CircuitBreaker) onSuccess() {
+	b.consecutive = 0
+
+	switch b.state {
+	case CircuitHalfOpen:
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.settings.HalfOpenRequests {
+			b.transition(CircuitClosed)
+		}
+	case CircuitClosed:
+		b.rollWindow()
+		b.requests++
+	}
+}
+
+func (b *CircuitBreaker) onFailure() {
+	switch b.state {
+	case CircuitHalfOpen:
+		b.transition(CircuitOpen)
+	case CircuitClosed:
+		b.rollWindow()
+		b.requests++
+		b.failures++
+		b.consecutive++
+		if b.shouldTrip() {
+			b.transition(CircuitOpen)
+		}
+	}
+}
+
+func (b *CircuitBreaker) shouldTrip() bool {
+	if b.settings.ConsecutiveFailures > 0 && b.consecutive >= b.settings.ConsecutiveFailures {
+		return true
+	}
+
+	return b.settings.ErrorRate > 0 && b.requests >= b.settings.MinRequests &&
+		float64(b.failures)/float64(b.requests) >= b.settings.ErrorRate
+}
+
+func (b *CircuitBreaker) rollWindow() {
+	if now := time.Now(); now.Sub(b.windowStart) >= b.settings.Window {
+		b.windowStart = now
+		b.requests = 0
+		b.failures = 0
+	}
+}
+
+func (b *CircuitBreaker) transition(state CircuitState) {
+	b.state = state
+	b.consecutive = 0
+	b.requests = 0
+	b.failures = 0
+	b.windowStart = time.Now()
+	b.halfOpenInFlight = 0
+	b.halfOpenSuccesses = 0
+	b.generation++
+
+	if state == CircuitOpen {
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,176 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stateRecorder struct {
+	mu          sync.Mutex
+	transitions []CircuitState
+}
+
+func (r *stateRecorder) record(from, to CircuitState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, to)
+}
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	var healthy int32
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := &stateRecorder{}
+	client := testClient(server, 0)
+	client.CircuitBreaker = NewCircuitBreaker(CircuitBreakerSettings{
+		ConsecutiveFailures: 3,
+		OpenTimeout:         50 * time.Millisecond,
+		OnStateChange:       recorder.record,
+	})
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		if err := client.request(req, nil); !errors.Is(err, F3StatusServiceUnavailable) {
+			t.Errorf("expected service unavailable error but got %v", err)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if err := client.request(req, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected open circuit to fail fast but got %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("open circuit should not reach the server, received %d requests", calls)
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(60 * time.Millisecond)
+
+	if state := client.CircuitBreaker.State(); state != CircuitHalfOpen {
+		t.Errorf("expected circuit to be half-open after the open timeout but was %s", state)
+	}
+
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	if err := client.request(req, nil); err != nil {
+		t.Errorf("half-open probe failed with %q", err)
+	}
+
+	if state := client.CircuitBreaker.State(); state != CircuitClosed {
+		t.Errorf("expected circuit to close after a successful probe but was %s", state)
+	}
+
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(recorder.transitions) != len(expected) {
+		t.Fatalf("expected transitions %v but got %v", expected, recorder.transitions)
+	}
+	for i, state := range expected {
+		if recorder.transitions[i] != state {
+			t.Errorf("expected transitions %v but got %v", expected, recorder.transitions)
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerSettings{
+		ConsecutiveFailures: 1,
+		OpenTimeout:         10 * time.Millisecond,
+	})
+
+	admitted, _ := breaker.allow()
+	breaker.done(context.Background(), admitted, F3StatusBadGateway)
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("expected circuit to open but was %s", state)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	probe, err := breaker.allow()
+	if err != nil {
+		t.Fatalf("half-open circuit should allow a probe but failed with %q", err)
+	}
+
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("half-open circuit should only allow a single probe in flight but got %v", err)
+	}
+
+	breaker.done(context.Background(), probe, F3StatusBadGateway)
+	if state := breaker.State(); state != CircuitOpen {
+		t.Errorf("expected failed probe to re-open the circuit but was %s", state)
+	}
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerSettings{
+		ErrorRate:   0.5,
+		MinRequests: 4,
+		Window:      time.Minute,
+	})
+
+	outcomes := []error{nil, F3StatusInternalServerError, nil, F3StatusNotFound, F3StatusGatewayTimeout, F3StatusBadGateway}
+	for _, outcome := range outcomes[:5] {
+		admitted, err := breaker.allow()
+		if err != nil {
+			t.Fatalf("closed circuit rejected request with %q", err)
+		}
+		breaker.done(context.Background(), admitted, outcome)
+	}
+
+	if state := breaker.State(); state != CircuitClosed {
+		t.Fatalf("2 failures out of 5 requests should not trip the breaker but it was %s", state)
+	}
+
+	admitted, _ := breaker.allow()
+	breaker.done(context.Background(), admitted, outcomes[5])
+	if state := breaker.State(); state != CircuitOpen {
+		t.Errorf("3 failures out of 6 requests should trip the breaker but it was %s", state)
+	}
+}
+
+func TestCircuitBreakerOnlyProbesFreeHalfOpenSlots(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerSettings{
+		ConsecutiveFailures: 1,
+		OpenTimeout:         10 * time.Millisecond,
+	})
+
+	slow, _ := breaker.allow()
+	failed, _ := breaker.allow()
+	breaker.done(context.Background(), failed, F3StatusBadGateway)
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := breaker.allow(); err != nil {
+		t.Fatalf("half-open circuit should allow a probe but failed with %q", err)
+	}
+
+	breaker.done(context.Background(), slow, nil)
+	if _, err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("a request admitted while closed should not free the probe slot, got %v", err)
+	}
+	if state := breaker.State(); state != CircuitHalfOpen {
+		t.Errorf("a request admitted while closed should not close the circuit, was %s", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresRateLimits(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerSettings{ConsecutiveFailures: 1})
+
+	admitted, _ := breaker.allow()
+	breaker.done(context.Background(), admitted, &F3RateLimitError{RetryAfter: time.Second})
+	if state := breaker.State(); state != CircuitClosed {
+		t.Errorf("a rate limited request should not open the circuit, was %s", state)
+	}
+}
@@ -1,13 +1,58 @@
 package form3
 
+import (
+	"errors"
+	"strings"
+)
+
 const (
 	ACCOUNTS = "accounts"
 )
 
-func logPayloadError(err error, response chan<- *Payload, errors chan<- []error) {
-	close(response)
-	errors <- []error { err }
-	close(errors)
+// ValidationErrors is returned by the blocking Do methods when a builder fails client side validation. It
+// matches any of the errors it holds with errors.Is and errors.As.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e ValidationErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func errorSlice(err error) []error {
+	var validationErrors ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return validationErrors
+	}
+	return []error{err}
+}
+
+func logPayload(payload *Payload, err error, response chan<- *Payload, errors chan<- []error) {
+	if err != nil {
+		logPayloadErrors(errorSlice(err), response, errors)
+		return
+	}
+	logPayloadResponse(payload, response, errors)
 }
 
 func logPayloadErrors(err []error, response chan<- *Payload, errors chan<- []error) {
@@ -20,4 +65,4 @@ func logPayloadResponse(payload *Payload, response chan<- *Payload, errors chan<
 	close(errors)
 	response <- payload
 	close(response)
-}
\ No newline at end of file
+}
@@ -0,0 +1,118 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateDoValidation(t *testing.T) {
+	client := SetupF3Client(F3Env{F3BaseURL: "localhost:0"})
+
+	payload, err := client.Create().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+		WithAccountClassification("Personal").
+		Do(context.Background())
+
+	if payload != nil {
+		t.Errorf("invalid builder should not return a payload %+v", payload)
+	}
+
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("expected validation errors but got %v", err)
+	}
+
+	if !errors.Is(err, countryFieldMissing) {
+		t.Errorf("expected validation errors to contain missing country but got %v", err)
+	}
+}
+
+func TestFetchDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/organisation/accounts/81d62ace-23f2-4aff-a7d6-60d7674bc5bb" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"data":{"id":"81d62ace-23f2-4aff-a7d6-60d7674bc5bb","version":3}}`)
+	}))
+	defer server.Close()
+
+	client := testClient(server, 0)
+	payload, err := client.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed with %q", err)
+	}
+
+	if payload.Data.Version != 3 {
+		t.Errorf("unexpected payload returned %+v", payload)
+	}
+
+	_, err = client.Fetch().WithAccountId("ea68b98a-471a-4c71-ac83-0f96a2bee973").Do(context.Background())
+	if !errors.Is(err, F3StatusNotFound) {
+		t.Errorf("expected not found error but got %v", err)
+	}
+}
+
+func TestDeleteRequestUnbufferedChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	errs := make(chan []error)
+	testClient(server, 0).Delete().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithVersion(1).
+		Request(context.Background(), errs)
+
+	if err := <-errs; len(err) != 1 || !errors.Is(err[0], F3StatusConflict) {
+		t.Errorf("expected a single conflict error but got %v", err)
+	}
+}
+
+func TestListPaginatorKeepsState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page[number]")
+		next := ""
+		if page != "2" {
+			next = fmt.Sprintf(`/v1/organisation/accounts?page[number]=%d`, map[string]int{"0": 1, "1": 2}[page])
+		}
+		_, _ = fmt.Fprintf(w, `{"data":[{"id":"page-%s"}],"links":{"next":%q}}`, page, next)
+	}))
+	defer server.Close()
+
+	page, paginator, err := testClient(server, 0).List().WithPageSize(1).Do(context.Background())
+	if err != nil {
+		t.Fatalf("list failed with %q", err)
+	}
+
+	var ids []UUID
+	for {
+		ids = append(ids, page.Data[0].Id)
+		if page.Links.Next == "" {
+			break
+		}
+
+		if page, paginator, err = paginator.NextPage(context.Background()); err != nil {
+			t.Fatalf("navigating to the next page failed with %q", err)
+		}
+	}
+
+	if fmt.Sprint(ids) != "[page-0 page-1 page-2]" {
+		t.Errorf("paginator did not walk every page, visited %v", ids)
+	}
+}
+
+func TestListValidateClosesChannel(t *testing.T) {
+	errors := make(chan []error, 1)
+	SetupF3Client(F3Env{}).List().Validate(errors)
+
+	if err, ok := <-errors; ok || err != nil {
+		t.Errorf("valid list builder should close the errors channel without errors but got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package form3
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// national check digit schemes validated by the EU bank detail validators
+const (
+	SchemeFrenchRIB     = "French RIB key"
+	SchemeSpanishCCC    = "Spanish CCC"
+	SchemeItalianCIN    = "Italian CIN"
+	SchemeBelgian       = "Belgian account"
+	SchemePortugueseNIB = "Portuguese NIB"
+)
+
+var digitsRegex = regexp.MustCompile("^[0-9]+$")
+var alphanumericRegex = regexp.MustCompile("^[A-Z0-9]+$")
+
+type InvalidCheckDigits struct {
+	Scheme      string
+	CheckDigits string
+}
+
+func (e *InvalidCheckDigits) Error() string {
+	return fmt.Sprintf("invalid %s check digits %q", e.Scheme, e.CheckDigits)
+}
+
+// optionalCheckDigitsValidation accepts an account number with or without its 2 national check digits appended,
+// the check digits only being validated on the extended form.
+func optionalCheckDigitsValidation(length int) stringValidation {
+	return stringValidation{
+		required:  false,
+		minLength: length,
+		maxLength: length + 2,
+		regex:     fmt.Sprintf("^.{%d}([0-9]{2})?$", length),
+	}
+}
+
+// leadingCheckDigitsValidation accepts an account number with or without its 2 national check digits in front of
+// it, as in a Spanish CCC.
+func leadingCheckDigitsValidation(length int) stringValidation {
+	return stringValidation{
+		required:  false,
+		minLength: length,
+		maxLength: length + 2,
+		regex:     fmt.Sprintf("^([0-9]{2})?.{%d}$", length),
+	}
+}
+
+// splitCheckDigits returns the account number and the check digits appended to it, if any.
+func splitCheckDigits(accountNumber string, length int) (string, string, bool) {
+	if len(accountNumber) != length+2 {
+		return accountNumber, "", false
+	}
+	return accountNumber[:length], accountNumber[length:], true
+}
+
+// isDigits reports whether the bank id is made up of exactly length digits, malformed bank ids are left to the
+// length validators.
+func isDigits(bankId BankId, length int) bool {
+	return len(bankId) == length && digitsRegex.MatchString(string(bankId))
+}
+
+func checkDigitsError(scheme string, expected string, actual string) (errors []error) {
+	if expected != actual {
+		errors = append(errors, &InvalidCheckDigits{Scheme: scheme, CheckDigits: actual})
+	}
+	return errors
+}
+
+// frenchAccountValidation accepts the 11 character French account number, or its 10 character form without the
+// leading zero, with or without the 2 digit RIB key appended.
+var frenchAccountValidation = stringValidation{
+	required:  false,
+	minLength: 10,
+	maxLength: 13,
+	regex:     "^.{10,11}([0-9]{2})?$",
+}
+
+func frenchRibKeyValidator(ab createBuilder) (errors []error) {
+	length := len(ab.AccountNumber) - 2
+	if length != 10 && length != 11 {
+		return errors
+	}
+
+	account, key, _ := splitCheckDigits(ab.AccountNumber, length)
+	if !isDigits(ab.BankId, 10) || !alphanumericRegex.MatchString(account) {
+		return errors
+	}
+	return checkDigitsError(SchemeFrenchRIB, frenchRibKey(string(ab.BankId), account), key)
+}
+
+// frenchRibKey computes the clé RIB of a 10 digit bank and branch code and an account number, letters in the
+// account number are first converted to digits.
+func frenchRibKey(bankId string, account string) string {
+	var converted strings.Builder
+	for _, r := range account {
+		if r >= 'A' && r <= 'Z' {
+			// A-I and J-R are converted to 1-9, S-Z to 2-9
+			converted.WriteByte(byte('1' + ((r-'A')%9+(r-'A')/18)%9))
+		} else {
+			converted.WriteRune(r)
+		}
+	}
+
+	remainder := (89*mod97(bankId[:5]) + 15*mod97(bankId[5:]) + 3*mod97(converted.String())) % 97
+	return fmt.Sprintf("%02d", 97-remainder)
+}
+
+func spanishCccValidator(ab createBuilder) (errors []error) {
+	if len(ab.AccountNumber) != 12 {
+		return errors
+	}
+
+	// the control digits precede the account number in a CCC
+	digits, account := ab.AccountNumber[:2], ab.AccountNumber[2:]
+	if !isDigits(ab.BankId, 8) || !digitsRegex.MatchString(ab.AccountNumber) {
+		return errors
+	}
+	return checkDigitsError(SchemeSpanishCCC, spanishCccDigits(string(ab.BankId), account), digits)
+}
+
+// spanishCccDigits computes the dígitos de control of an 8 digit entity and office code and a 10 digit account.
+func spanishCccDigits(bankId string, account string) string {
+	weights := []int{1, 2, 4, 8, 5, 10, 9, 7, 3, 6}
+	digit := func(s string) int {
+		total := 0
+		for i, r := range s {
+			total += int(r-'0') * weights[i]
+		}
+
+		switch d := 11 - total%11; d {
+		case 11:
+			return 0
+		case 10:
+			return 1
+		default:
+			return d
+		}
+	}
+	return fmt.Sprintf("%d%d", digit("00"+bankId), digit(account))
+}
+
+func italianCinValidator(ab createBuilder) (errors []error) {
+	bankId := string(ab.BankId)
+	if len(bankId) != 11 || len(ab.AccountNumber) != 12 || !alphanumericRegex.MatchString(bankId) ||
+		!alphanumericRegex.MatchString(ab.AccountNumber) {
+		return errors
+	}
+	return checkDigitsError(SchemeItalianCIN, italianCin(bankId[1:], ab.AccountNumber), bankId[:1])
+}
+
+// italianCin computes the CIN of a 10 digit ABI and CAB code and a 12 character account number.
+func italianCin(bankId string, account string) string {
+	odd := []int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23}
+
+	total := 0
+	for i, r := range bankId + account {
+		value := int(r - '0')
+		if r >= 'A' && r <= 'Z' {
+			value = int(r - 'A')
+		}
+
+		if i%2 == 0 {
+			total += odd[value]
+		} else {
+			total += value
+		}
+	}
+	return string(rune('A' + total%26))
+}
+
+func belgianCheckValidator(ab createBuilder) (errors []error) {
+	account, digits, ok := splitCheckDigits(ab.AccountNumber, 7)
+	if !ok || !isDigits(ab.BankId, 3) || !digitsRegex.MatchString(account) {
+		return errors
+	}
+	return checkDigitsError(SchemeBelgian, belgianCheckDigits(string(ab.BankId), account), digits)
+}
+
+// belgianCheckDigits computes the mod-97 check of a 3 digit bank code and a 7 digit account number, a remainder
+// of 0 is written as 97.
+func belgianCheckDigits(bankId string, account string) string {
+	remainder := mod97(bankId + account)
+	if remainder == 0 {
+		remainder = 97
+	}
+	return fmt.Sprintf("%02d", remainder)
+}
+
+func portugueseNibValidator(ab createBuilder) (errors []error) {
+	account, digits, ok := splitCheckDigits(ab.AccountNumber, 11)
+	if !ok || !isDigits(ab.BankId, 8) || !digitsRegex.MatchString(account) {
+		return errors
+	}
+	return checkDigitsError(SchemePortugueseNIB, portugueseNibDigits(string(ab.BankId), account), digits)
+}
+
+// portugueseNibDigits computes the check digits of an 8 digit bank and branch code and an 11 digit account.
+func portugueseNibDigits(bankId string, account string) string {
+	return fmt.Sprintf("%02d", 98-mod97(bankId+account+"00"))
+}
+
+// mod97 returns the remainder of a string of digits divided by 97.
+func mod97(digits string) int {
+	remainder := 0
+	for _, r := range digits {
+		remainder = (remainder*10 + int(r-'0')) % 97
+	}
+	return remainder
+}
@@ -0,0 +1,82 @@
+package form3
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNationalCheckDigitValidation(t *testing.T) {
+	accounts := []struct {
+		scenario      string
+		validator     Validator
+		bankId        string
+		accountNumber string
+		expectError   bool
+	}{
+		{"Valid French RIB key", frenchRibKeyValidator, "2004101005", "500013M02606", false},
+		{"Invalid French RIB key", frenchRibKeyValidator, "2004101005", "500013M02607", true},
+		{"French account without RIB key", frenchRibKeyValidator, "2004101005", "500013M026", false},
+		{"Valid French RIB key of an 11 character account", frenchRibKeyValidator, "2004101005", "0500013M02606", false},
+		{"Invalid French RIB key of an 11 character account", frenchRibKeyValidator, "2004101005", "0500013M02607", true},
+		{"Valid Spanish CCC", spanishCccValidator, "21000418", "450200051332", false},
+		{"Invalid Spanish CCC", spanishCccValidator, "21000418", "540200051332", true},
+		{"Spanish account without CCC", spanishCccValidator, "21000418", "0200051332", false},
+		{"Valid Italian CIN", italianCinValidator, "X0542811101", "000000123456", false},
+		{"Invalid Italian CIN", italianCinValidator, "Y0542811101", "000000123456", true},
+		{"Italian account without CIN", italianCinValidator, "0542811101", "", false},
+		{"Valid Belgian check", belgianCheckValidator, "539", "007547034", false},
+		{"Invalid Belgian check", belgianCheckValidator, "539", "007547043", true},
+		{"Belgian account without check", belgianCheckValidator, "539", "0075470", false},
+		{"Valid Portuguese NIB", portugueseNibValidator, "00020123", "1234567890154", false},
+		{"Invalid Portuguese NIB", portugueseNibValidator, "00020123", "1234567890145", true},
+		{"Portuguese account without NIB", portugueseNibValidator, "00020123", "12345678901", false},
+		{"Malformed bank id is left to the length validator", frenchRibKeyValidator, "2004", "500013M02606", false},
+	}
+
+	var invalidCheckDigits *InvalidCheckDigits
+	for _, a := range accounts {
+		t.Run(a.scenario, func(t *testing.T) {
+			errs := a.validator(createBuilder{AccountAttributes: AccountAttributes{
+				BankId: BankId(a.bankId), AccountNumber: a.accountNumber,
+			}})
+
+			switch a.expectError {
+			case true:
+				if len(errs) != 1 || !errors.As(errs[0], &invalidCheckDigits) {
+					t.Errorf("validation did not fail with *InvalidCheckDigits! %s %s: %v", a.bankId, a.accountNumber, errs)
+				}
+			case false:
+				if len(errs) > 0 {
+					t.Errorf("validation failed with %q on valid account %s %s", errs, a.bankId, a.accountNumber)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateBuilderRunsNationalCheckDigits(t *testing.T) {
+	errs := postValidators(createBuilder{AccountAttributes: AccountAttributes{
+		Country: "ES", BankId: "21000418", BankIdCode: "ESNCC", AccountNumber: "540200051332",
+		AccountClassification: "Personal",
+	}})
+
+	for _, err := range errs {
+		if validationRule(err) == "spanishCcc" {
+			return
+		}
+	}
+	t.Errorf("expected a spanishCcc validation error, got %v", errs)
+}
+
+func TestSpanishCheckDigitsLeadTheAccountNumber(t *testing.T) {
+	validation := accountNumberLengthMap[Countries["ES"]]
+	for _, accountNumber := range []string{"0200051332", "450200051332"} {
+		if errs := stringValidator(accountNumber, validation); len(errs) > 0 {
+			t.Errorf("expected %q to be accepted, got %v", accountNumber, errs)
+		}
+	}
+
+	if errs := stringValidator("AB0200051332", validation); len(errs) == 0 {
+		t.Errorf("expected check digits which are not leading digits to be rejected")
+	}
+}
@@ -2,69 +2,110 @@ package form3
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	F3BaseURL    = "F3BaseURL"
-	F3Timeout    = "F3Timeout"
-	F3MaxRetries = "F3MaxRetries"
+	F3BaseURL       = "F3BaseURL"
+	F3Timeout       = "F3Timeout"
+	F3MaxRetries    = "F3MaxRetries"
+	F3RetryWaitMin  = "F3RetryWaitMin"
+	F3RetryWaitMax  = "F3RetryWaitMax"
+	F3RateLimit     = "F3RateLimit"
+	F3RateBurst     = "F3RateBurst"
+	F3CACert        = "F3CACert"
+	F3ClientCert    = "F3ClientCert"
+	F3ClientKey     = "F3ClientKey"
+	F3TLSMinVersion = "F3TLSMinVersion"
+	F3TokenURL      = "F3TokenURL"
+	F3ClientID      = "F3ClientID"
+	F3ClientSecret  = "F3ClientSecret"
+	F3Scopes        = "F3Scopes"
+	F3SigningKeyId  = "F3SigningKeyId"
+	F3SigningKey    = "F3SigningKey"
 )
 
 type F3Env struct {
-	F3BaseURL    string
-	F3Timeout    time.Duration
-	F3MaxRetries int
+	F3BaseURL      string
+	F3Timeout      time.Duration
+	F3MaxRetries   int
+	F3RetryWaitMin time.Duration
+	F3RetryWaitMax time.Duration
+	F3RateLimit    float64
+	F3RateBurst    int
 }
 
-var clientEnv F3Env
-var Logger *log.Logger
+// Logger receives the package's log messages unless a client is given its own through WithLogger.
+var Logger StructuredLogger
 
 func init() {
-	Logger = log.New(os.Stderr, "F3CLIENT: ", log.Ldate|log.Ltime|log.Lshortfile)
+	Logger = NewStdLogger(log.New(os.Stderr, "F3CLIENT: ", log.Ldate|log.Ltime), LevelInfo)
 }
 
 type F3Client struct {
-	Env        F3Env
-	HTTPClient *http.Client
+	Env                  F3Env
+	HTTPClient           *http.Client
+	RateLimiter          *RateLimiter
+	CircuitBreaker       *CircuitBreaker
+	Authenticator        Authenticator
+	Signer               *RequestSigner
+	logger               StructuredLogger
+	userAgent            string
+	clock                Clock
+	middlewares          []Middleware
+	Metrics              Metrics
+	Tracer               Tracer
+	organisationLimiters sync.Map
+	modulusTable         *modulusTable
 }
 
 func SetupF3Client(env F3Env) *F3Client {
-	return &F3Client{
+	client := &F3Client{
 		Env: env,
 		HTTPClient: &http.Client{
-			Timeout: clientEnv.F3Timeout,
+			Timeout: env.F3Timeout,
 		},
 	}
+
+	if env.F3RateLimit > 0 {
+		client.RateLimiter = NewRateLimiter(env.F3RateLimit, env.F3RateBurst)
+	}
+
+	return client
 }
 
+// NewF3Client creates a client configured from the F3* environmental variables. Unlike New(FromEnv()), malformed
+// timeouts, retries and rate limits are logged and replaced with their defaults.
 func NewF3Client() (*F3Client, error) {
-	clientEnv.F3BaseURL = os.Getenv(F3BaseURL)
-	if clientEnv.F3BaseURL == "" {
-		Logger.Printf("'F3BaseURL' environmental variable not set")
-		return nil, fmt.Errorf("'F3BaseURL' environmental variable not set")
+	client, err := New(fromEnv(false))
+	if err != nil {
+		Logger.Log(LevelError, "failed to configure client from the environment", F("error", err))
+		return nil, err
 	}
+	return client, nil
+}
 
-	if duration, err := time.ParseDuration(os.Getenv(F3Timeout)); err == nil {
-		clientEnv.F3Timeout = duration
-	} else {
-		Logger.Printf("F3Timeout environment variable not set. defaulting to 60 seconds")
-		clientEnv.F3Timeout = 60 * time.Second
+// log returns the logger given to WithLogger, falling back to the package Logger, behind the redaction layer.
+func (c *F3Client) log() StructuredLogger {
+	if c == nil || c.logger == nil {
+		return NewRedactingLogger(Logger)
 	}
+	return NewRedactingLogger(c.logger)
+}
 
-	if retries, err := strconv.Atoi(os.Getenv(F3MaxRetries)); err == nil {
-		clientEnv.F3MaxRetries = retries
-	} else {
-		Logger.Printf("F3MaxRetries environment variable not set. defaulting to 3 retries")
-		clientEnv.F3MaxRetries = 3
+func (c *F3Client) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
 	}
-
-	return SetupF3Client(clientEnv), nil
+	return c.clock.Now()
 }
 
 func (c *F3Client) Create() CreateBuilder {
@@ -79,72 +120,230 @@ func (c *F3Client) List() ListBuilder {
 	return newListBuilder(c)
 }
 
+func (c *F3Client) Update() UpdateBuilder {
+	return newUpdateBuilder(c)
+}
+
 func (c *F3Client) Delete() DeleteBuilder {
 	return newDeleteBuilder(c)
 }
 
+// baseURL accepts F3BaseURL either as a bare host such as 'localhost:8080', which is served over http, or as
+// a full url with a scheme and an optional path prefix such as 'https://api.form3.tech/gateway'.
+func (c *F3Client) baseURL() *url.URL {
+	base := c.Env.F3BaseURL
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return &url.URL{Scheme: "http", Host: c.Env.F3BaseURL}
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u
+}
+
+func (c *F3Client) resourceURL(path string) string {
+	base := c.baseURL()
+	return fmt.Sprintf("%s://%s%s%s", base.Scheme, base.Host, base.Path, path)
+}
+
+// linkURL resolves a pagination link returned by the api against F3BaseURL. Only the path and query of the
+// link are used, so that links never redirect the client to another host.
+func (c *F3Client) linkURL(link string) string {
+	if u, err := url.Parse(link); err == nil && u.IsAbs() {
+		link = u.RequestURI()
+	}
+
+	base := c.baseURL()
+	if base.Path != "" && !strings.HasPrefix(link, base.Path+"/") {
+		link = base.Path + link
+	}
+	return fmt.Sprintf("%s://%s%s", base.Scheme, base.Host, link)
+}
+
 func (c *F3Client) request(req *http.Request, body interface{}) error {
-	req.Header.Set("Host", c.Env.F3BaseURL)
-	req.Header.Set("Date", time.Now().Format(time.RFC1123))
+	if err := rewindableBody(req); err != nil {
+		c.log().Log(LevelError, "error buffering request body", F("method", req.Method), F("error", err))
+		return fmt.Errorf("error buffering request body. error: %w", err)
+	}
+
+	reauthenticated := false
+	for attempt := 0; ; attempt++ {
+		if err := c.throttle(req.Context()); err != nil {
+			return err
+		}
+
+		ctx, span := c.startSpan(req.Context(), "form3.http", F("http.method", req.Method),
+			F("f3.retry_attempt", attempt))
+		status, err := c.guardedAttempt(req.WithContext(ctx), body)
+		if status > 0 {
+			span.SetAttributes(F("http.status_code", status))
+		}
+		endSpan(span, err)
+
+		if call := SpanFromContext(req.Context()); call != nil && c.Tracer != nil {
+			call.SetAttributes(F("f3.retry_attempt", attempt))
+			if status > 0 {
+				call.SetAttributes(F("http.status_code", status))
+			}
+		}
+
+		if c.Authenticator != nil && !reauthenticated && errors.Is(err, F3StatusUnauthorized) {
+			c.log().Log(LevelWarn, "access token rejected, retrying request with a fresh token", F("method", req.Method))
+			reauthenticated = true
+			attempt--
+			continue
+		}
+
+		if err == nil || attempt >= c.Env.F3MaxRetries || !isRetryable(req.Context(), err) {
+			return err
+		}
+
+		wait, ok := c.retryWait(req.Context(), attempt+1, err)
+		if !ok {
+			c.log().Log(LevelWarn, "rate limit wait exceeds the retry wait, giving up", F("method", req.Method),
+				F("wait", wait), F("error", err))
+			return err
+		}
+
+		c.observeRetry(req.Context())
+		c.log().Log(LevelWarn, "retrying request", F("method", req.Method), F("wait", wait),
+			F("attempt", attempt+1), F("attempts", c.Env.F3MaxRetries+1), F("error", err))
+		if err := sleep(req.Context(), wait); err != nil {
+			return err
+		}
+	}
+}
+
+// guardedAttempt returns the status code of the response, or 0 when none was received, along with the error.
+func (c *F3Client) guardedAttempt(req *http.Request, body interface{}) (int, error) {
+	if c.CircuitBreaker == nil {
+		return c.attempt(req, body)
+	}
+
+	admitted, err := c.CircuitBreaker.allow()
+	if err != nil {
+		c.log().Log(LevelWarn, "circuit breaker rejected request", F("method", req.Method))
+		return 0, err
+	}
+
+	status, err := c.attempt(req, body)
+	c.CircuitBreaker.done(req.Context(), admitted, err)
+	return status, err
+}
+
+func (c *F3Client) attempt(req *http.Request, body interface{}) (int, error) {
+	req, err := cloneRequest(req)
+	if err != nil {
+		c.log().Log(LevelError, "error rewinding request body", F("method", req.Method), F("error", err))
+		return 0, fmt.Errorf("error rewinding request body. error: %w", err)
+	}
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("Date", c.now().UTC().Format(http.TimeFormat))
 	req.Header.Set("Accept", "application/vnd.api+json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if span := SpanFromContext(req.Context()); span != nil && span.SpanContext().IsValid() {
+		req.Header.Set(headerTraceParent, span.SpanContext().TraceParent())
+	}
 
-	res, err := c.HTTPClient.Do(req)
+	start := time.Now()
+	res, err := c.doer().Do(req)
 	if err != nil {
-		Logger.Printf("error fetching request")
-		return err
+		c.observeRequest(req.Context(), 0, start)
+		return 0, err
 	}
+	c.observeRequest(req.Context(), res.StatusCode, start)
 	defer func() {
 		res.Body.Close()
 	}()
 
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		return mapF3Error(res)
+		return res.StatusCode, mapF3Error(res, c.now())
 	}
 
 	if body != nil {
 		if err = json.NewDecoder(res.Body).Decode(body); err != nil {
-			Logger.Printf("error decoding response body")
-			return fmt.Errorf("error decoding json body. error: %w", err)
+			c.log().Log(LevelError, "error decoding response body", F("method", req.Method), F("error", err))
+			return res.StatusCode, fmt.Errorf("error decoding json body. error: %w", err)
 		}
 	}
 
-	return nil
+	return res.StatusCode, nil
 }
 
-func mapF3Error(res *http.Response) error {
+// send is the innermost Doer of the middleware chain, authenticating and signing the request before handing it
+// to HTTPClient.
+func (c *F3Client) send(req *http.Request) (*http.Response, error) {
+	if c.Authenticator != nil {
+		if err := c.Authenticator.Authenticate(req); err != nil {
+			c.log().Log(LevelError, "error authenticating request", F("method", req.Method), F("error", err))
+			return nil, fmt.Errorf("error authenticating request. error: %w", err)
+		}
+	}
+
+	if c.Signer != nil {
+		if err := c.Signer.Sign(req); err != nil {
+			c.log().Log(LevelError, "error signing request", F("method", req.Method), F("error", err))
+			return nil, err
+		}
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		c.log().Log(LevelError, "error fetching request", F("method", req.Method), F("error", err))
+		return nil, redactError(err)
+	}
+
+	if res.StatusCode == http.StatusUnauthorized && c.Authenticator != nil {
+		c.Authenticator.Invalidate(req)
+	}
+
+	return res, nil
+}
+
+func mapF3Error(res *http.Response, now time.Time) error {
+	apiErr, decodeErr := newF3APIError(res)
+
 	switch res.StatusCode {
 	case http.StatusBadRequest:
-		var errRes = &F3StatusBadRequest{}
-		if err := json.NewDecoder(res.Body).Decode(errRes); err == nil {
-			return errRes
+		if decodeErr == nil {
+			apiErr.err = &F3StatusBadRequest{ErrorCode: apiErr.ErrorCode, ErrorMessage: apiErr.ErrorMessage}
 		} else {
-			return fmt.Errorf("corrupted payload for bad request. %w", &F3StatusBadRequest{})
+			apiErr.err = fmt.Errorf("corrupted payload for bad request. %w", &F3StatusBadRequest{})
 		}
 	case http.StatusUnauthorized:
-		return F3StatusUnauthorized
+		apiErr.err = F3StatusUnauthorized
 	case http.StatusForbidden:
-		return F3StatusForbidden
+		apiErr.err = F3StatusForbidden
 	case http.StatusNotFound:
-		return F3StatusNotFound
+		apiErr.err = F3StatusNotFound
 	case http.StatusMethodNotAllowed:
-		return F3StatusMethodNotAllowed
+		apiErr.err = F3StatusMethodNotAllowed
 	case http.StatusNotAcceptable:
-		return F3StatusNotAcceptable
+		apiErr.err = F3StatusNotAcceptable
 	case http.StatusConflict:
-		return F3StatusConflict
+		apiErr.err = F3StatusConflict
 	case http.StatusTooManyRequests:
-		return F3StatusTooManyRequests
+		apiErr.err = newF3RateLimitError(res.Header, now)
 	case http.StatusInternalServerError:
-		return F3StatusInternalServerError
+		apiErr.err = F3StatusInternalServerError
 	case http.StatusBadGateway:
-		return F3StatusBadGateway
+		apiErr.err = F3StatusBadGateway
 	case http.StatusServiceUnavailable:
-		return F3StatusServiceUnavailable
+		apiErr.err = F3StatusServiceUnavailable
 	case http.StatusGatewayTimeout:
-		return F3StatusGatewayTimeout
+		apiErr.err = F3StatusGatewayTimeout
 	default:
-		return fmt.Errorf("status code: %d, unsupported error: %w", res.StatusCode, F3UnsupportedError)
+		apiErr.err = fmt.Errorf("status code: %d, unsupported error: %w", res.StatusCode, F3UnsupportedError)
 	}
+
+	return apiErr
 }
 
 type PaginatedPayload struct {
@@ -185,6 +384,22 @@ func (e *F3StatusBadRequest) Error() string {
 		"\nerrorCode: %q\nerrorMessage: %q", e.ErrorCode, e.ErrorMessage)
 }
 
+type F3RateLimitError struct {
+	RetryAfter time.Duration
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+}
+
+func (e *F3RateLimitError) Error() string {
+	return fmt.Sprintf("%s\nretryAfter: %s limit: %d remaining: %d reset: %s",
+		F3StatusTooManyRequests, e.RetryAfter, e.Limit, e.Remaining, e.Reset.Format(time.RFC1123))
+}
+
+func (e *F3RateLimitError) Unwrap() error {
+	return F3StatusTooManyRequests
+}
+
 var F3StatusUnauthorized = fmt.Errorf("unauthorized. returned when trying to access api endpoints with an invalid or expired access token")
 var F3StatusForbidden = fmt.Errorf("forbidden. returned when trying to obtain an access token with incorrect client credentials")
 var F3StatusNotFound = fmt.Errorf("not found. returned when trying to access a non-existent endpoint or resource. returned in the validation api when a queried sort code cannot be found")
@@ -2,9 +2,26 @@ package form3
 
 import (
 	"context"
+	"fmt"
+	"time"
 )
 
-func ExampleF3CreateAccount() {
+func ExampleNew() {
+	sandbox, err := New(FromEnv(), WithBaseURL("https://api.staging-form3.tech"), WithTimeout(10*time.Second))
+	if err != nil {
+		return
+	}
+
+	production, err := New(FromEnv(), WithBaseURL("https://api.form3.tech"), WithMaxRetries(5))
+	if err != nil {
+		return
+	}
+
+	_, _ = sandbox.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+	_, _ = production.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+}
+
+func ExampleCreateBuilder_Request() {
 	f3Client, err := NewF3Client()
 	if err != nil {
 		return
@@ -27,7 +44,7 @@ func ExampleF3CreateAccount() {
 	<-errors
 }
 
-func ExampleF3FetchAccount() {
+func ExampleFetchBuilder_Request() {
 	f3Client, err := NewF3Client()
 	if err != nil {
 		return
@@ -44,7 +61,7 @@ func ExampleF3FetchAccount() {
 	<-errors
 }
 
-func ExampleF3ListAccount() {
+func ExampleListBuilder_Request() {
 	f3Client, err := NewF3Client()
 	if err != nil {
 		return
@@ -68,7 +85,7 @@ func ExampleF3ListAccount() {
 	<-lastErrors
 }
 
-func ExampleF3DeleteAccount() {
+func ExampleDeleteBuilder_Request() {
 	f3Client, err := NewF3Client()
 	if err != nil {
 		return
@@ -83,3 +100,60 @@ func ExampleF3DeleteAccount() {
 
 	<-errors
 }
+
+func ExampleCreateBuilder_Do() {
+	f3Client, err := NewF3Client()
+	if err != nil {
+		return
+	}
+
+	payload, err := f3Client.Create().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+		WithCountry(Countries["GB"]).
+		WithBankId("000006").
+		WithBic("NWBKGB22").
+		WithBankIdCode("GBDSC").
+		WithAccountClassification("Personal").
+		Do(context.Background())
+	if err != nil {
+		return
+	}
+
+	fmt.Println(payload.Data.Id)
+}
+
+func ExampleListBuilder_Do() {
+	f3Client, err := NewF3Client()
+	if err != nil {
+		return
+	}
+
+	page, paginator, err := f3Client.List().
+		WithPageSize(10).
+		Do(context.Background())
+	if err != nil {
+		return
+	}
+
+	for page.Links.Next != "" {
+		if page, paginator, err = paginator.NextPage(context.Background()); err != nil {
+			return
+		}
+	}
+}
+
+func ExampleDeleteBuilder_Do() {
+	f3Client, err := NewF3Client()
+	if err != nil {
+		return
+	}
+
+	err = f3Client.Delete().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithVersion(0).
+		Do(context.Background())
+	if err != nil {
+		fmt.Println(err)
+	}
+}
@@ -14,6 +14,7 @@ type createBuilder struct {
 	OrganisationId UUID
 	Type           string
 	AccountId      UUID
+	deriveIban     bool
 }
 
 type CreateBuilder interface {
@@ -24,6 +25,8 @@ type CreateBuilder interface {
 	WithAccountNumber(accountNumber string) CreateBuilder
 	WithBic(bic SwiftCode) CreateBuilder
 	WithIban(iban IBAN) CreateBuilder
+	WithDerivedIban() CreateBuilder
+	FromIban(iban IBAN) CreateBuilder
 	WithCustomerId(customerId string) CreateBuilder
 	WithName(name Identifier) CreateBuilder
 	WithAlternativeNames(alternativeName Identifier) CreateBuilder
@@ -38,6 +41,8 @@ type CreateBuilder interface {
 	UnsafeRequest(ctx context.Context, response chan<- *Payload, errors chan<- []error) CreateBuilder
 	Request(ctx context.Context, response chan<- *Payload, errors chan<- []error) CreateBuilder
 	Validate(errors chan<- []error) CreateBuilder
+	UnsafeDo(ctx context.Context) (*Payload, error)
+	Do(ctx context.Context) (*Payload, error)
 }
 
 func newAccountBuilder(client *F3Client) CreateBuilder {
@@ -48,32 +53,99 @@ func newAccountBuilder(client *F3Client) CreateBuilder {
 }
 
 func (ab createBuilder) UnsafeRequest(ctx context.Context, response chan<- *Payload, errors chan<- []error) CreateBuilder {
-	go ab.internalRequest(build(ab), ctx, response, errors)
+	go func() {
+		payload, err := ab.UnsafeDo(ctx)
+		logPayload(payload, err, response, errors)
+	}()
+	return ab
+}
+
+func (ab createBuilder) Request(ctx context.Context, response chan<- *Payload, errors chan<- []error) CreateBuilder {
+	go func() {
+		payload, err := ab.Do(ctx)
+		logPayload(payload, err, response, errors)
+	}()
 	return ab
 }
 
 func (ab createBuilder) Validate(errors chan<- []error) CreateBuilder {
-	var errs []error
+	if err := ab.validate(); len(err) > 0 {
+		errors <- err
+	}
+
+	close(errors)
+	return ab
+}
+
+func (ab createBuilder) UnsafeDo(ctx context.Context) (payload *Payload, err error) {
+	ctx, span := ab.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(F("f3.validation", "skipped"))
+
+	if ab, err = ab.withDerivedIban(); err != nil {
+		return nil, err
+	}
+	return ab.internalRequest(build(ab), ctx)
+}
+
+func (ab createBuilder) Do(ctx context.Context) (payload *Payload, err error) {
+	ctx, span := ab.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+
+	errs := ab.validate()
+	setValidationOutcome(span, errs)
+	if len(errs) > 0 {
+		return nil, ValidationErrors(errs)
+	}
+
+	// derivation failures have already been reported by validate
+	ab, _ = ab.withDerivedIban()
+	return ab.internalRequest(build(ab), ctx)
+}
+
+func (ab createBuilder) startSpan(ctx context.Context) (context.Context, Span) {
+	return ab.client.startSpan(ctx, "form3.create", F("f3.operation", OperationCreate),
+		F("f3.account_id", string(ab.AccountId)), F("f3.organisation_id", string(ab.OrganisationId)))
+}
+
+func (ab createBuilder) validate() (errors []error) {
 	if ab.client == nil {
-		errs = append(errs, fmt.Errorf("F3Client not set"))
+		errors = append(errors, ruleError("client", fmt.Errorf("F3Client not set")))
 	}
 
-	if err := postValidators(ab); len(err) > 0 {
-		errors <- append(errs, err...)
+	ab, err := ab.withDerivedIban()
+	if err != nil {
+		errors = append(errors, ruleError("derivedIban", err))
 	}
 
-	close(errors)
-	return ab
+	errors = append(errors, postValidators(ab)...)
+	ab.client.observeValidation(OperationCreate, errors)
+	return errors
 }
 
-func (ab createBuilder) Request(ctx context.Context, response chan<- *Payload, errors chan<- []error) CreateBuilder {
-	if err := postValidators(ab); len(err) > 0 {
-		logPayloadErrors(err, response, errors)
-	} else {
-		go ab.internalRequest(build(ab), ctx, response, errors)
+// withDerivedIban fills the IBAN from the bank id and account number when WithDerivedIban was requested. The bank
+// code of GB, IE and NL IBANs is taken from the BIC.
+func (ab createBuilder) withDerivedIban() (createBuilder, error) {
+	if !ab.deriveIban {
+		return ab, nil
 	}
 
-	return ab
+	if rejectsIban(ab.Country) {
+		return ab, fmt.Errorf("an IBAN cannot be derived for %q accounts, iban should be empty", ab.Country)
+	}
+
+	if bbanStartsWithBic(ab.Country) && ab.Bic.IsZeroValue() {
+		return ab, fmt.Errorf("deriving an IBAN for %q accounts requires the bank code of the BIC %w",
+			ab.Country, bicFieldMissing)
+	}
+
+	iban, err := BuildIBAN(ab.Country, ab.Bic, ab.BankId, ab.AccountNumber)
+	if err != nil {
+		return ab, err
+	}
+
+	ab.Iban = iban
+	return ab, nil
 }
 
 func build(u createBuilder) *Payload {
@@ -104,31 +176,28 @@ func build(u createBuilder) *Payload {
 	}
 }
 
-func (ab createBuilder) internalRequest(reqPayload *Payload, ctx context.Context, response chan<- *Payload, errors chan<- []error) {
+func (ab createBuilder) internalRequest(reqPayload *Payload, ctx context.Context) (*Payload, error) {
 	byteArray, err := json.Marshal(reqPayload)
 	if err != nil {
-		Logger.Println("error marshalling json payload for account creation")
-		logPayloadError(fmt.Errorf("error marshalling payload: %+v - error: %w", reqPayload, err), response, errors)
-		return
+		ab.client.log().Log(LevelError, "error marshalling json payload for account creation", F("error", err))
+		return nil, fmt.Errorf("error marshalling payload for account %q - error: %w", reqPayload.Data.Id, err)
 	}
 
-	url := fmt.Sprintf("http://%s/v1/organisation/accounts", ab.client.Env.F3BaseURL)
+	url := ab.client.resourceURL("/v1/organisation/accounts")
 	req, err := http.NewRequest("POST", url, bytes.NewReader(byteArray))
 	if err != nil {
-		Logger.Printf("failed to creat new http request for %q", url)
-		logPayloadError(fmt.Errorf("error creating request Method: 'Post' Url: %q - error: %w", url, err), response, errors)
-		return
+		ab.client.log().Log(LevelError, "failed to create new http request", F("url", url), F("error", err))
+		return nil, fmt.Errorf("error creating request Method: 'Post' Url: %q - error: %w", redact(url), redactError(err))
 	}
 
-	req = req.WithContext(ctx)
+	req = req.WithContext(withOperation(WithOrganisation(ctx, ab.OrganisationId), OperationCreate))
 	res := &Payload{}
 	if err := ab.client.request(req, res); err != nil {
-		Logger.Printf("error requesting POST %q", url)
-		logPayloadError(err, response, errors)
-		return
+		ab.client.log().Log(LevelError, "error requesting POST", F("url", url), F("error", err))
+		return nil, err
 	}
 
-	logPayloadResponse(res, response, errors)
+	return res, nil
 }
 
 func (ab createBuilder) WithCountry(country Country) CreateBuilder {
@@ -166,6 +235,26 @@ func (ab createBuilder) WithIban(iban IBAN) CreateBuilder {
 	return ab
 }
 
+func (ab createBuilder) WithDerivedIban() CreateBuilder {
+	ab.deriveIban = true
+	return ab
+}
+
+// FromIban sets the IBAN and fills the Country, BankId and AccountNumber from it. An invalid IBAN is kept as is to
+// be reported by validation.
+func (ab createBuilder) FromIban(iban IBAN) CreateBuilder {
+	ab.Iban = iban
+
+	components, err := ParseIBAN(iban)
+	if err != nil {
+		return ab
+	}
+
+	ab.Country = components.Country
+	ab.BankId, ab.AccountNumber = components.domesticDetails()
+	return ab
+}
+
 func (ab createBuilder) WithCustomerId(customerId string) CreateBuilder {
 	ab.CustomerId = customerId
 	return ab
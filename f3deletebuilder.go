@@ -19,6 +19,8 @@ type DeleteBuilder interface {
 	UnsafeRequest(ctx context.Context, errors chan<- []error) DeleteBuilder
 	Request(ctx context.Context, errors chan<- []error) DeleteBuilder
 	Validate(errors chan<- []error) DeleteBuilder
+	UnsafeDo(ctx context.Context) error
+	Do(ctx context.Context) error
 }
 
 func newDeleteBuilder(client *F3Client) DeleteBuilder {
@@ -38,18 +40,43 @@ func (d deleteBuilder) WithVersion(version int) DeleteBuilder {
 }
 
 func (d deleteBuilder) UnsafeRequest(ctx context.Context, errors chan<- []error) DeleteBuilder {
-	go d.internalRequest(ctx, errors)
+	go func() {
+		logResult(d.UnsafeDo(ctx), errors)
+	}()
 	return d
 }
 
 func (d deleteBuilder) Request(ctx context.Context, errors chan<- []error) DeleteBuilder {
-	if err := d.validate(); len(err) > 0 {
-		logErrors(err, errors)
-		return d
-	} else {
-		go d.internalRequest(ctx, errors)
-		return d
+	go func() {
+		logResult(d.Do(ctx), errors)
+	}()
+	return d
+}
+
+func (d deleteBuilder) UnsafeDo(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(F("f3.validation", "skipped"))
+
+	return d.internalRequest(ctx)
+}
+
+func (d deleteBuilder) Do(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+
+	errs := d.validate()
+	setValidationOutcome(span, errs)
+	if len(errs) > 0 {
+		return ValidationErrors(errs)
 	}
+
+	return d.internalRequest(ctx)
+}
+
+func (d deleteBuilder) startSpan(ctx context.Context) (context.Context, Span) {
+	return d.client.startSpan(ctx, "form3.delete", F("f3.operation", OperationDelete),
+		F("f3.account_id", string(d.AccountId)), F("f3.version", d.Version))
 }
 
 func (d deleteBuilder) Validate(errors chan<- []error) DeleteBuilder {
@@ -62,40 +89,42 @@ func (d deleteBuilder) Validate(errors chan<- []error) DeleteBuilder {
 
 func (d deleteBuilder) validate() (errors []error) {
 	if d.client == nil {
-		errors = append(errors, fmt.Errorf("F3Client not set"))
+		errors = append(errors, ruleError("client", fmt.Errorf("F3Client not set")))
 	}
 
 	if d.AccountId.IsZeroValue() {
-		errors = append(errors, fmt.Errorf("missing account id in fetch request %w", accountIdFieldMissing))
+		errors = append(errors, ruleError("accountId", fmt.Errorf("missing account id in fetch request %w", accountIdFieldMissing)))
 	} else if err := d.AccountId.IsValid(); err != nil {
-		errors = append(errors, err)
+		errors = append(errors, ruleError("accountId", err))
 	}
 
+	d.client.observeValidation(OperationDelete, errors)
 	return errors
 }
 
-func (d deleteBuilder) internalRequest(ctx context.Context, errors chan<- []error) {
-	url := fmt.Sprintf("http://%s/v1/organisation/accounts/%s?version=%d",
-		d.client.Env.F3BaseURL, url.QueryEscape(string(d.AccountId)), d.Version)
+func (d deleteBuilder) internalRequest(ctx context.Context) error {
+	url := d.client.resourceURL(fmt.Sprintf("/v1/organisation/accounts/%s?version=%d",
+		url.PathEscape(string(d.AccountId)), d.Version))
 	req, err := http.NewRequest("DELETE", url, nil)
 	if err != nil {
-		Logger.Printf("failed to creat new delete request for %q", url)
-		logError(fmt.Errorf("error posting request Method: 'DELETE' Url: %q - error: %w", url, err), errors)
-		return
+		d.client.log().Log(LevelError, "failed to create new delete request", F("url", url), F("error", err))
+		return fmt.Errorf("error posting request Method: 'DELETE' Url: %q - error: %w", redact(url), redactError(err))
 	}
 
-	req = req.WithContext(ctx)
+	req = req.WithContext(withOperation(ctx, OperationDelete))
 	if err := d.client.request(req, nil); err != nil {
-		Logger.Printf("error requesting GET %q", url)
-		logError(err, errors)
-		return
+		d.client.log().Log(LevelError, "error requesting DELETE", F("url", url), F("error", err))
+		return err
 	}
 
-	close(errors)
+	return nil
 }
 
-func logError(err error, errors chan<- []error) {
-	errors <- []error{err}
+func logResult(err error, errors chan<- []error) {
+	if err != nil {
+		logErrors(errorSlice(err), errors)
+		return
+	}
 	close(errors)
 }
 
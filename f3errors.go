@@ -0,0 +1,92 @@
+package form3
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const (
+	headerRequestId = "X-Request-Id"
+
+	// maxErrorBodySize bounds how much of an error response is kept on F3APIError.
+	maxErrorBodySize = 4096
+)
+
+// F3APIError describes a non 2xx response returned by the api. It wraps the documented status error, so
+// existing checks such as errors.Is(err, F3StatusNotFound) or errors.As(err, &badRequest) keep working.
+type F3APIError struct {
+	StatusCode   int
+	Method       string
+	URL          string
+	ErrorCode    string
+	ErrorMessage string
+	Header       http.Header
+	RequestId    string
+	Body         []byte
+	err          error
+}
+
+func newF3APIError(res *http.Response) (*F3APIError, error) {
+	apiErr := &F3APIError{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		RequestId:  res.Header.Get(headerRequestId),
+	}
+
+	if res.Request != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.URL = res.Request.URL.String()
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	if err != nil {
+		return apiErr, err
+	}
+	apiErr.Body = body
+
+	var errRes F3StatusBadRequest
+	if err := json.Unmarshal(body, &errRes); err != nil {
+		return apiErr, err
+	}
+	apiErr.ErrorCode = errRes.ErrorCode
+	apiErr.ErrorMessage = errRes.ErrorMessage
+
+	return apiErr, nil
+}
+
+func (e *F3APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s returned status code %d", e.Method, redact(e.URL), e.StatusCode)
+	if e.RequestId != "" {
+		fmt.Fprintf(&sb, " (request id %q)", e.RequestId)
+	}
+
+	if e.err != nil {
+		fmt.Fprintf(&sb, ": %s", redact(e.err.Error()))
+	} else if e.ErrorMessage != "" {
+		fmt.Fprintf(&sb, ": %s", redact(e.ErrorMessage))
+	}
+	return sb.String()
+}
+
+func (e *F3APIError) Unwrap() error {
+	return e.err
+}
+
+// Retryable reports whether the api documents the status as safe to retry after waiting.
+func (e *F3APIError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (e *F3APIError) Temporary() bool {
+	return e.Retryable()
+}
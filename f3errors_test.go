@@ -0,0 +1,114 @@
+package form3
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestF3APIError(t *testing.T) {
+	statuses := []struct {
+		scenario  string
+		status    int
+		body      string
+		sentinel  error
+		retryable bool
+	}{
+		{"Unauthorized", http.StatusUnauthorized, "", F3StatusUnauthorized, false},
+		{"Forbidden", http.StatusForbidden, "", F3StatusForbidden, false},
+		{"Not Found", http.StatusNotFound, `{"error_message":"record does not exist"}`, F3StatusNotFound, false},
+		{"Method Not Allowed", http.StatusMethodNotAllowed, "", F3StatusMethodNotAllowed, false},
+		{"Not Acceptable", http.StatusNotAcceptable, "", F3StatusNotAcceptable, false},
+		{"Conflict", http.StatusConflict, `{"error_message":"invalid version"}`, F3StatusConflict, false},
+		{"Too Many Requests", http.StatusTooManyRequests, "", F3StatusTooManyRequests, true},
+		{"Internal Server Error", http.StatusInternalServerError, "", F3StatusInternalServerError, true},
+		{"Bad Gateway", http.StatusBadGateway, "<html>bad gateway</html>", F3StatusBadGateway, true},
+		{"Service Unavailable", http.StatusServiceUnavailable, "", F3StatusServiceUnavailable, true},
+		{"Gateway Timeout", http.StatusGatewayTimeout, "", F3StatusGatewayTimeout, true},
+		{"Unsupported", http.StatusTeapot, "", F3UnsupportedError, false},
+	}
+
+	for _, s := range statuses {
+		t.Run(s.scenario, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(headerRequestId, "5c8c2b2c-5c4d-4a77-a0a1-1ee4b0de7a4c")
+				w.WriteHeader(s.status)
+				_, _ = w.Write([]byte(s.body))
+			}))
+			defer server.Close()
+
+			req, _ := http.NewRequest("GET", server.URL+"/v1/organisation/accounts", nil)
+			err := testClient(server, 0).request(req, nil)
+
+			var apiErr *F3APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an F3APIError but got %v", err)
+			}
+
+			if !errors.Is(err, s.sentinel) {
+				t.Errorf("F3APIError should match the %q sentinel, got %v", s.sentinel, err)
+			}
+
+			if apiErr.StatusCode != s.status || apiErr.Method != "GET" || !strings.HasSuffix(apiErr.URL, "/v1/organisation/accounts") {
+				t.Errorf("unexpected request details on error %+v", apiErr)
+			}
+
+			if apiErr.RequestId != "5c8c2b2c-5c4d-4a77-a0a1-1ee4b0de7a4c" || !strings.Contains(err.Error(), apiErr.RequestId) {
+				t.Errorf("request id missing from error %q", err)
+			}
+
+			if string(apiErr.Body) != s.body {
+				t.Errorf("expected body %q but got %q", s.body, apiErr.Body)
+			}
+
+			if apiErr.Retryable() != s.retryable || apiErr.Temporary() != s.retryable {
+				t.Errorf("expected retryable to be %t", s.retryable)
+			}
+		})
+	}
+}
+
+func TestF3APIErrorBadRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error_code":"validation_failure","error_message":"iban in body should match"}`))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("POST", server.URL, nil)
+	err := testClient(server, 0).request(req, nil)
+
+	var badRequest *F3StatusBadRequest
+	if !errors.As(err, &badRequest) {
+		t.Fatalf("expected 'F3StatusBadRequest' error but got %v", err)
+	}
+
+	if badRequest.ErrorMessage != "iban in body should match" || badRequest.ErrorCode != "validation_failure" {
+		t.Errorf("bad request details lost %+v", badRequest)
+	}
+
+	var apiErr *F3APIError
+	if errors.As(err, &apiErr); apiErr.ErrorMessage != badRequest.ErrorMessage {
+		t.Errorf("api error message %q does not match bad request %q", apiErr.ErrorMessage, badRequest.ErrorMessage)
+	}
+}
+
+func TestF3APIErrorBoundedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(strings.Repeat("x", 3*maxErrorBodySize)))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	var apiErr *F3APIError
+	if err := testClient(server, 0).request(req, nil); !errors.As(err, &apiErr) {
+		t.Fatalf("expected an F3APIError but got %v", err)
+	}
+
+	if len(apiErr.Body) != maxErrorBodySize {
+		t.Errorf("expected body to be bounded to %d bytes but was %d", maxErrorBodySize, len(apiErr.Body))
+	}
+}
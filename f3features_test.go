@@ -7,24 +7,50 @@ import (
 	"github.com/cucumber/godog"
 	"github.com/cucumber/messages-go/v10"
 	"github.com/google/uuid"
+	"github.com/shawnritchie/interview-accountapi-master/cassette"
+	"math/rand"
 	"os"
 )
 
+// F3Cassette points the feature tests at a cassette file, which F3CassetteMode either records against the
+// docker compose stack ('record') or replays offline ('replay', the default).
+const (
+	F3Cassette     = "F3Cassette"
+	F3CassetteMode = "F3CassetteMode"
+)
+
+var recorder *cassette.Recorder
+
 func init() {
 	if os.Getenv("F3BaseURL") == "" {
-		Logger.Printf("F3BaseURL environment variable not set. defaulting to 'localhost:8080'")
+		Logger.Log(LevelWarn, "F3BaseURL environment variable not set. defaulting to 'localhost:8080'")
 		_ = os.Setenv("F3BaseURL", "localhost:8080")
 	}
 
 	if os.Getenv("F3Timeout") == "" {
-		Logger.Printf("F3BaseURL environment variable not set. defaulting to '60s'")
+		Logger.Log(LevelWarn, "F3Timeout environment variable not set. defaulting to '60s'")
 		_ = os.Setenv("F3Timeout", "60s")
 	}
 
 	if os.Getenv("F3MaxRetries") == "" {
-		Logger.Printf("F3BaseURL environment variable not set. defaulting to '3'")
+		Logger.Log(LevelWarn, "F3MaxRetries environment variable not set. defaulting to '3'")
 		_ = os.Setenv("F3MaxRetries", "3")
 	}
+
+	if path := os.Getenv(F3Cassette); path != "" {
+		mode, err := cassette.ParseMode(os.Getenv(F3CassetteMode))
+		if err != nil {
+			panic(err)
+		}
+
+		if recorder, err = cassette.New(path, mode, nil); err != nil {
+			panic(err)
+		}
+
+		// random ids must be identical between recording and replaying for requests to match, while every recording
+		// picks a new seed so that it does not conflict with the accounts of a previous one
+		uuid.SetRand(rand.New(rand.NewSource(recorder.Seed())))
+	}
 }
 
 func validAccount(f3Client *F3Client) CreateBuilder {
@@ -47,7 +73,12 @@ type f3ClientState struct {
 }
 
 func (state *f3ClientState) anInitiatedClient() error {
-	f3Client, err := NewF3Client()
+	options := []Option{FromEnv()}
+	if recorder != nil {
+		options = append(options, WithTransport(recorder))
+	}
+
+	f3Client, err := New(options...)
 	if err != nil {
 		return fmt.Errorf("F3Client could not be initalized please check environmental variables 'F3BaseURL' is set up correct %w", err)
 	}
@@ -341,7 +372,13 @@ func (state *f3ClientState) weExpectValidationErrors() error {
 }
 
 func (state *f3ClientState) weValidateTheAccountBuilderWithProperties(requestType string, accounts *messages.PickleStepArgument_PickleTable) error {
-	builder := state.F3Client.Create().
+	// validation never reaches the API, so builder only scenarios do not need an initiated client
+	client := state.F3Client
+	if client == nil {
+		client = SetupF3Client(F3Env{F3BaseURL: "localhost:8080"})
+	}
+
+	builder := client.Create().
 		WithAccountId(state.accountId).
 		WithOrganisationId(state.organisationId)
 
@@ -382,6 +419,18 @@ func (state *f3ClientState) weValidateTheAccountBuilderWithProperties(requestTyp
 	return nil
 }
 
+func InitializeTestSuite(ctx *godog.TestSuiteContext) {
+	ctx.AfterSuite(func() {
+		if recorder == nil {
+			return
+		}
+
+		if err := recorder.Save(); err != nil {
+			Logger.Log(LevelError, "failed to save cassette", F("error", err))
+		}
+	})
+}
+
 func InitializeScenario(ctx *godog.ScenarioContext) {
 	state := &f3ClientState{}
 
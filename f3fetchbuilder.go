@@ -17,6 +17,8 @@ type FetchBuilder interface {
 	UnsafeRequest(ctx context.Context, response chan<- *Payload, errors chan<- []error) FetchBuilder
 	Request(ctx context.Context, response chan<- *Payload, errors chan<- []error) FetchBuilder
 	Validate(errors chan<- []error) FetchBuilder
+	UnsafeDo(ctx context.Context) (*Payload, error)
+	Do(ctx context.Context) (*Payload, error)
 }
 
 func newFetchBuilder(client *F3Client) FetchBuilder {
@@ -31,7 +33,10 @@ func (fb fetchBuilder) WithAccountId(accountId UUID) FetchBuilder {
 }
 
 func (fb fetchBuilder) UnsafeRequest(ctx context.Context, response chan<- *Payload, errors chan<- []error) FetchBuilder {
-	go fb.internalRequest(ctx, response, errors)
+	go func() {
+		payload, err := fb.UnsafeDo(ctx)
+		logPayload(payload, err, response, errors)
+	}()
 	return fb
 }
 
@@ -44,45 +49,68 @@ func (fb fetchBuilder) Validate(errors chan<- []error) FetchBuilder {
 }
 
 func (fb fetchBuilder) Request(ctx context.Context, response chan<- *Payload, errors chan<- []error) FetchBuilder {
-	if err := fb.validate(); len(err) > 0 {
-		logPayloadErrors(err, response, errors)
-		return fb
-	} else {
-		go fb.internalRequest(ctx, response, errors)
-		return fb
+	go func() {
+		payload, err := fb.Do(ctx)
+		logPayload(payload, err, response, errors)
+	}()
+	return fb
+}
+
+func (fb fetchBuilder) UnsafeDo(ctx context.Context) (payload *Payload, err error) {
+	ctx, span := fb.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(F("f3.validation", "skipped"))
+
+	return fb.internalRequest(ctx)
+}
+
+func (fb fetchBuilder) Do(ctx context.Context) (payload *Payload, err error) {
+	ctx, span := fb.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+
+	errs := fb.validate()
+	setValidationOutcome(span, errs)
+	if len(errs) > 0 {
+		return nil, ValidationErrors(errs)
 	}
+
+	return fb.internalRequest(ctx)
+}
+
+func (fb fetchBuilder) startSpan(ctx context.Context) (context.Context, Span) {
+	return fb.client.startSpan(ctx, "form3.fetch", F("f3.operation", OperationFetch),
+		F("f3.account_id", string(fb.AccountId)))
 }
 
 func (fb fetchBuilder) validate() (errors []error) {
 	if fb.client == nil {
-		errors = append(errors, fmt.Errorf("F3Client not set"))
+		errors = append(errors, ruleError("client", fmt.Errorf("F3Client not set")))
 	}
 
 	if fb.AccountId.IsZeroValue() {
-		errors = append(errors, fmt.Errorf("missing account id in fetch request %w", accountIdFieldMissing))
+		errors = append(errors, ruleError("accountId", fmt.Errorf("missing account id in fetch request %w", accountIdFieldMissing)))
 	} else if err := fb.AccountId.IsValid(); err != nil {
-		errors = append(errors, err)
+		errors = append(errors, ruleError("accountId", err))
 	}
 
+	fb.client.observeValidation(OperationFetch, errors)
 	return errors
 }
 
-func (fb fetchBuilder) internalRequest(ctx context.Context, response chan<- *Payload, errors chan<- []error) {
-	url := fmt.Sprintf("http://%s/v1/organisation/accounts/%s", fb.client.Env.F3BaseURL, url.QueryEscape(string(fb.AccountId)))
+func (fb fetchBuilder) internalRequest(ctx context.Context) (*Payload, error) {
+	url := fb.client.resourceURL(fmt.Sprintf("/v1/organisation/accounts/%s", url.PathEscape(string(fb.AccountId))))
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		Logger.Printf("failed to creat new http request for %q", url)
-		logPayloadError(fmt.Errorf("error creating request Method: 'GET' Url: %q - error: %w", url, err), response, errors)
-		return
+		fb.client.log().Log(LevelError, "failed to create new http request", F("url", url), F("error", err))
+		return nil, fmt.Errorf("error creating request Method: 'GET' Url: %q - error: %w", redact(url), redactError(err))
 	}
 
-	req = req.WithContext(ctx)
+	req = req.WithContext(withOperation(ctx, OperationFetch))
 	res := &Payload{}
 	if err := fb.client.request(req, res); err != nil {
-		Logger.Printf("error requesting GET %q", url)
-		logPayloadError(err, response, errors)
-		return
+		fb.client.log().Log(LevelError, "error requesting GET", F("url", url), F("error", err))
+		return nil, err
 	}
 
-	logPayloadResponse(res, response, errors)
+	return res, nil
 }
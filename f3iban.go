@@ -0,0 +1,362 @@
+package form3
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IbanRule names the ISO 13616 rule an IBAN failed.
+type IbanRule string
+
+const (
+	IbanFormat          IbanRule = "ibanFormat"
+	IbanCountry         IbanRule = "ibanCountry"
+	IbanLength          IbanRule = "ibanLength"
+	IbanBBAN            IbanRule = "ibanBban"
+	IbanChecksum        IbanRule = "ibanChecksum"
+	IbanCountryMismatch IbanRule = "ibanCountryMismatch"
+)
+
+type InvalidIBAN struct {
+	iban   IBAN
+	Rule   IbanRule
+	reason string
+}
+
+func (e *InvalidIBAN) Error() string {
+	return fmt.Sprintf("invalid IBAN %q. %s", maskIban(string(e.iban)), e.reason)
+}
+
+func invalidIban(iban IBAN, rule IbanRule, format string, args ...interface{}) error {
+	return &InvalidIBAN{iban: iban, Rule: rule, reason: fmt.Sprintf(format, args...)}
+}
+
+type ibanFormat struct {
+	length int
+	bban   string
+}
+
+// ibanRegistry holds the IBAN length and BBAN structure of each country, as published in the SWIFT IBAN
+// registry. The structure uses the registry notation, where 4!a is four upper case letters, 6!n six digits and
+// 12!c twelve alphanumeric characters.
+var ibanRegistry = map[string]ibanFormat{
+	"AD": {24, "4!n4!n12!c"},
+	"AE": {23, "3!n16!n"},
+	"AL": {28, "8!n16!c"},
+	"AT": {20, "5!n11!n"},
+	"AZ": {28, "4!a20!c"},
+	"BA": {20, "3!n3!n8!n2!n"},
+	"BE": {16, "3!n7!n2!n"},
+	"BG": {22, "4!a4!n2!n8!c"},
+	"BH": {22, "4!a14!c"},
+	"BR": {29, "8!n5!n10!n1!a1!c"},
+	"BY": {28, "4!c4!n16!c"},
+	"CH": {21, "5!n12!c"},
+	"CR": {22, "4!n14!n"},
+	"CY": {28, "3!n5!n16!c"},
+	"CZ": {24, "4!n6!n10!n"},
+	"DE": {22, "8!n10!n"},
+	"DK": {18, "4!n9!n1!n"},
+	"DO": {28, "4!c20!n"},
+	"EE": {20, "2!n2!n11!n1!n"},
+	"EG": {29, "4!n4!n17!n"},
+	"ES": {24, "4!n4!n1!n1!n10!n"},
+	"FI": {18, "3!n11!n"},
+	"FO": {18, "4!n9!n1!n"},
+	"FR": {27, "5!n5!n11!c2!n"},
+	"GB": {22, "4!a6!n8!n"},
+	"GE": {22, "2!a16!n"},
+	"GI": {23, "4!a15!c"},
+	"GL": {18, "4!n9!n1!n"},
+	"GR": {27, "3!n4!n16!c"},
+	"GT": {28, "4!c20!c"},
+	"HR": {21, "7!n10!n"},
+	"HU": {28, "3!n4!n1!n15!n1!n"},
+	"IE": {22, "4!a6!n8!n"},
+	"IL": {23, "3!n3!n13!n"},
+	"IQ": {23, "4!a3!n12!n"},
+	"IS": {26, "4!n2!n6!n10!n"},
+	"IT": {27, "1!a5!n5!n12!c"},
+	"JO": {30, "4!a4!n18!c"},
+	"KW": {30, "4!a22!c"},
+	"KZ": {20, "3!n13!c"},
+	"LB": {28, "4!n20!c"},
+	"LC": {32, "4!a24!c"},
+	"LI": {21, "5!n12!c"},
+	"LT": {20, "5!n11!n"},
+	"LU": {20, "3!n13!c"},
+	"LV": {21, "4!a13!c"},
+	"MC": {27, "5!n5!n11!c2!n"},
+	"MD": {24, "2!c18!c"},
+	"ME": {22, "3!n13!n2!n"},
+	"MK": {19, "3!n10!c2!n"},
+	"MR": {27, "5!n5!n11!n2!n"},
+	"MT": {31, "4!a5!n18!c"},
+	"MU": {30, "4!a2!n2!n12!n3!n3!a"},
+	"NL": {18, "4!a10!n"},
+	"NO": {15, "4!n6!n1!n"},
+	"PK": {24, "4!a16!c"},
+	"PL": {28, "8!n16!n"},
+	"PS": {29, "4!a21!c"},
+	"PT": {25, "4!n4!n11!n2!n"},
+	"QA": {29, "4!a21!c"},
+	"RO": {24, "4!a16!c"},
+	"RS": {22, "3!n13!n2!n"},
+	"SA": {24, "2!n18!c"},
+	"SC": {31, "4!a2!n2!n16!n3!a"},
+	"SE": {24, "3!n16!n1!n"},
+	"SI": {19, "5!n8!n2!n"},
+	"SK": {24, "4!n6!n10!n"},
+	"SM": {27, "1!a5!n5!n12!c"},
+	"ST": {25, "4!n4!n11!n2!n"},
+	"SV": {28, "4!a20!n"},
+	"TL": {23, "3!n14!n2!n"},
+	"TN": {24, "2!n3!n13!n2!n"},
+	"TR": {26, "5!n1!n16!c"},
+	"UA": {29, "6!n19!c"},
+	"VA": {22, "3!n15!n"},
+	"VG": {24, "4!a16!n"},
+	"XK": {20, "4!n10!n2!n"},
+}
+
+var bbanPatterns = compileBbanPatterns(ibanRegistry)
+
+var bbanElement = regexp.MustCompile(`(\d+)!([nac])`)
+
+func compileBbanPatterns(registry map[string]ibanFormat) map[string]*regexp.Regexp {
+	patterns := make(map[string]*regexp.Regexp, len(registry))
+	for country, format := range registry {
+		patterns[country] = regexp.MustCompile("^" + bbanRegex(format.bban) + "$")
+	}
+	return patterns
+}
+
+// bbanRegex translates a BBAN structure in registry notation into a regular expression.
+func bbanRegex(structure string) string {
+	classes := map[string]string{"n": "[0-9]", "a": "[A-Z]", "c": "[A-Za-z0-9]"}
+
+	var sb strings.Builder
+	for _, element := range bbanElement.FindAllStringSubmatch(structure, -1) {
+		sb.WriteString(fmt.Sprintf("%s{%s}", classes[element[2]], element[1]))
+	}
+	return sb.String()
+}
+
+func validateIban(iban IBAN) error {
+	s := string(iban)
+	if match, _ := regexp.MatchString(ibanRegex, s); !match {
+		return invalidIban(iban, IbanFormat, "iban should match %q", ibanRegex)
+	}
+
+	country := s[:2]
+	format, ok := ibanRegistry[country]
+	if !ok {
+		return invalidIban(iban, IbanCountry, "country %q does not issue IBANs", country)
+	}
+
+	if len(s) != format.length {
+		return invalidIban(iban, IbanLength, "Length: %d - %s IBANs are %d characters long", len(s), country,
+			format.length)
+	}
+
+	if !bbanPatterns[country].MatchString(s[4:]) {
+		return invalidIban(iban, IbanBBAN, "%s BBAN should have the structure %q", country, format.bban)
+	}
+
+	if ibanMod97(s) != 1 {
+		return invalidIban(iban, IbanChecksum, "check digits %q do not match", s[2:4])
+	}
+
+	return nil
+}
+
+// ibanMod97 computes the ISO 7064 mod 97-10 remainder of an IBAN, which is 1 for valid check digits.
+func ibanMod97(iban string) int {
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		digits := strconv.Itoa(ibanDigit(r))
+		for _, d := range digits {
+			remainder = (remainder*10 + int(d-'0')) % 97
+		}
+	}
+	return remainder
+}
+
+func ibanDigit(r rune) int {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0')
+	case r >= 'A' && r <= 'Z':
+		return int(r-'A') + 10
+	default:
+		return int(r-'a') + 10
+	}
+}
+
+// ibanCountryMatches checks that the IBAN was issued in the country the account is opened in.
+func ibanCountryMatches(iban IBAN, country Country) error {
+	if len(iban) < 2 || iban[:2] == IBAN(country) {
+		return nil
+	}
+	return invalidIban(iban, IbanCountryMismatch, "IBAN country %q does not match account country %q",
+		iban[:2], country)
+}
+
+// BuildIBAN derives an IBAN from a domestic bank id and account number, following the BBAN layout of the country
+// and computing the check digits. The BBAN of GB, IE and NL starts with the bank code of the BIC, which is
+// required for these countries and ignored for the others. National check digits missing from the account number are
+// computed for FR, ES, IT, BE and PT.
+func BuildIBAN(country Country, bic SwiftCode, bankId BankId, accountNumber string) (IBAN, error) {
+	if _, ok := ibanRegistry[string(country)]; !ok {
+		return "", invalidIban("", IbanCountry, "country %q does not issue IBANs", country)
+	}
+
+	if bbanStartsWithBic(country) {
+		if len(bic) < 4 {
+			return "", invalidIban("", IbanBBAN, "the BBAN of %q accounts starts with the bank code of the BIC",
+				country)
+		}
+		bankId = BankId(bic[:4]) + bankId
+	}
+
+	bban := domesticBban(country, string(bankId), accountNumber)
+	checkDigits := 98 - ibanMod97(string(country)+"00"+bban)
+	iban := IBAN(fmt.Sprintf("%s%02d%s", country, checkDigits, bban))
+	if err := validateIban(iban); err != nil {
+		return "", err
+	}
+	return iban, nil
+}
+
+// bbanStartsWithBic reports whether the BBAN of the country starts with the bank code of the BIC.
+func bbanStartsWithBic(country Country) bool {
+	return country == "GB" || country == "IE" || country == "NL"
+}
+
+// domesticBban lays out the BBAN of a country from its bank id and account number. Malformed details are passed
+// through untouched and rejected by the registry once the IBAN is validated.
+func domesticBban(country Country, bankId string, account string) string {
+	switch country {
+	case "BE":
+		if len(account) == 7 && isDigits(BankId(bankId), 3) && digitsRegex.MatchString(account) {
+			account += belgianCheckDigits(bankId, account)
+		}
+	case "DE":
+		account = zeroPad(account, 10)
+	case "ES":
+		if len(account) == 10 && isDigits(BankId(bankId), 8) && digitsRegex.MatchString(account) {
+			account = spanishCccDigits(bankId, account) + account
+		}
+	case "FR":
+		if (len(account) == 10 || len(account) == 11) && isDigits(BankId(bankId), 10) &&
+			alphanumericRegex.MatchString(account) {
+			account += frenchRibKey(bankId, account)
+		}
+		// the RIB account number is 11 characters long, followed by the 2 digit key
+		account = zeroPad(account, 13)
+	case "IT":
+		if len(bankId) == 10 && len(account) == 12 && alphanumericRegex.MatchString(bankId+account) {
+			bankId = italianCin(bankId, account) + bankId
+		}
+	case "PT":
+		if len(account) == 11 && isDigits(BankId(bankId), 8) && digitsRegex.MatchString(account) {
+			account += portugueseNibDigits(bankId, account)
+		}
+	}
+	return bankId + account
+}
+
+func zeroPad(s string, length int) string {
+	if len(s) >= length {
+		return s
+	}
+	return strings.Repeat("0", length-len(s)) + s
+}
+
+// IBANComponents are the parts of an IBAN, as laid out by the BBAN of its country. Components the country does not
+// use are left empty, as are all but the account number for countries without a known layout.
+type IBANComponents struct {
+	Country             Country
+	CheckDigits         string
+	BankCode            string
+	BranchCode          string
+	AccountNumber       string
+	NationalCheckDigits string
+}
+
+// span is the [start, end) position of a component inside a BBAN, the zero span marks an unused component.
+type span struct {
+	start, end int
+}
+
+func (s span) of(bban string) string {
+	return bban[s.start:s.end]
+}
+
+type bbanLayout struct {
+	bank, branch, account, check span
+}
+
+// bbanLayouts positions the components of the BBAN of the countries validated by postValidators.
+var bbanLayouts = map[string]bbanLayout{
+	"BE": {bank: span{0, 3}, account: span{3, 10}, check: span{10, 12}},
+	"CH": {bank: span{0, 5}, account: span{5, 17}},
+	"DE": {bank: span{0, 8}, account: span{8, 18}},
+	"ES": {bank: span{0, 4}, branch: span{4, 8}, check: span{8, 10}, account: span{10, 20}},
+	"FR": {bank: span{0, 5}, branch: span{5, 10}, account: span{10, 21}, check: span{21, 23}},
+	"GB": {bank: span{0, 4}, branch: span{4, 10}, account: span{10, 18}},
+	"GR": {bank: span{0, 3}, branch: span{3, 7}, account: span{7, 23}},
+	"IE": {bank: span{0, 4}, branch: span{4, 10}, account: span{10, 18}},
+	"IT": {check: span{0, 1}, bank: span{1, 6}, branch: span{6, 11}, account: span{11, 23}},
+	"LU": {bank: span{0, 3}, account: span{3, 16}},
+	"NL": {bank: span{0, 4}, account: span{4, 14}},
+	"PL": {bank: span{0, 8}, account: span{8, 24}},
+	"PT": {bank: span{0, 4}, branch: span{4, 8}, account: span{8, 19}, check: span{19, 21}},
+}
+
+// ParseIBAN validates an IBAN and splits it into its components.
+func ParseIBAN(iban IBAN) (*IBANComponents, error) {
+	if err := validateIban(iban); err != nil {
+		return nil, err
+	}
+
+	s := string(iban)
+	bban := s[4:]
+	components := &IBANComponents{Country: Country(s[:2]), CheckDigits: s[2:4], AccountNumber: bban}
+
+	if layout, ok := bbanLayouts[s[:2]]; ok {
+		components.BankCode = layout.bank.of(bban)
+		components.BranchCode = layout.branch.of(bban)
+		components.AccountNumber = layout.account.of(bban)
+		components.NationalCheckDigits = layout.check.of(bban)
+	}
+	return components, nil
+}
+
+// domesticDetails maps the components of an IBAN onto the BankId and AccountNumber expected by postValidators,
+// the reverse of domesticBban. The bank code of GB, IE and NL IBANs belongs to the BIC and is left out.
+func (c *IBANComponents) domesticDetails() (BankId, string) {
+	switch c.Country {
+	case "GB", "IE":
+		return BankId(c.BranchCode), c.AccountNumber
+	case "NL":
+		return "", c.AccountNumber
+	case "ES":
+		return BankId(c.BankCode + c.BranchCode), c.NationalCheckDigits + c.AccountNumber
+	case "DE":
+		// the 10 digit Kontonummer is zero padded, accounts are validated in their 7 digit form when it fits
+		if strings.HasPrefix(c.AccountNumber, "000") {
+			return BankId(c.BankCode), c.AccountNumber[3:]
+		}
+		return BankId(c.BankCode), c.AccountNumber
+	case "FR":
+		// the 11 character numéro de compte followed by the 2 digit clé RIB
+		return BankId(c.BankCode + c.BranchCode), c.AccountNumber + c.NationalCheckDigits
+	case "IT":
+		return BankId(c.NationalCheckDigits + c.BankCode + c.BranchCode), c.AccountNumber
+	default:
+		return BankId(c.BankCode + c.BranchCode), c.AccountNumber + c.NationalCheckDigits
+	}
+}
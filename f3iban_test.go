@@ -0,0 +1,327 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIbanRules(t *testing.T) {
+	ibans := []struct {
+		scenario string
+		iban     string
+		rule     IbanRule
+	}{
+		{"Valid GB", "GB82WEST12345698765432", ""},
+		{"Valid DE", "DE89370400440532013000", ""},
+		{"Valid FR", "FR1420041010050500013M02606", ""},
+		{"Valid NO", "NO9386011117947", ""},
+		{"Valid MT", "MT84MALT011000012345MTLCAST001S", ""},
+		{"Lower case", "gb82west12345698765432", IbanFormat},
+		{"Missing country", "00000000000000000020", IbanFormat},
+		{"Unknown country", "US64SVBKUS6S3300958879", IbanCountry},
+		{"Too short", "GB82WEST1234569876543", IbanLength},
+		{"Too long", "GB82WEST123456987654321", IbanLength},
+		{"GB bank code must be letters", "GB82WE5T12345698765432", IbanBBAN},
+		{"GB account number must be digits", "GB82WEST1234569876543A", IbanBBAN},
+		{"Zero check digits", "GB00000000000000000020", IbanBBAN},
+		{"Bad check digits", "GB83WEST12345698765432", IbanChecksum},
+		{"Transposed digits", "GB82WEST12345698765423", IbanChecksum},
+	}
+
+	for _, i := range ibans {
+		t.Run(i.scenario, func(t *testing.T) {
+			iban := IBAN(i.iban)
+			err := iban.IsValid()
+
+			if i.rule == "" {
+				if err != nil {
+					t.Errorf("validation failed with %q on valid IBAN %q", err, i.iban)
+				}
+				return
+			}
+
+			var invalidIban *InvalidIBAN
+			if !errors.As(err, &invalidIban) {
+				t.Fatalf("expected an *InvalidIBAN for %q, got %v", i.iban, err)
+			}
+			if invalidIban.Rule != i.rule {
+				t.Errorf("expected rule %q for %q, got %q: %v", i.rule, i.iban, invalidIban.Rule, err)
+			}
+			if strings.Contains(err.Error(), i.iban) {
+				t.Errorf("expected the iban to be masked in %q", err)
+			}
+		})
+	}
+}
+
+func TestIbanRegistryLengthsMatchBban(t *testing.T) {
+	for country, format := range ibanRegistry {
+		length := 4
+		for _, element := range bbanElement.FindAllStringSubmatch(format.bban, -1) {
+			var n int
+			for _, d := range element[1] {
+				n = n*10 + int(d-'0')
+			}
+			length += n
+		}
+
+		if length != format.length {
+			t.Errorf("%s: registry length %d does not match BBAN structure %q (%d)", country, format.length,
+				format.bban, length)
+		}
+	}
+}
+
+func TestIbanCountryMustMatchAccountCountry(t *testing.T) {
+	errs := postValidators(createBuilder{AccountAttributes: AccountAttributes{
+		Country: "DE", BankId: "37040044", BankIdCode: "DEBLZ", Iban: "GB82WEST12345698765432",
+	}})
+
+	var invalidIban *InvalidIBAN
+	for _, err := range errs {
+		if errors.As(err, &invalidIban) && invalidIban.Rule == IbanCountryMismatch {
+			return
+		}
+	}
+	t.Errorf("expected an %q error, got %v", IbanCountryMismatch, errs)
+}
+
+func TestBuildIBAN(t *testing.T) {
+	ibans := []struct {
+		scenario      string
+		country       Country
+		bic           SwiftCode
+		bankId        BankId
+		accountNumber string
+		iban          IBAN
+		rule          IbanRule
+	}{
+		{"GB bank code and sort code", "GB", "WESTGB22", "123456", "98765432", "GB82WEST12345698765432", ""},
+		{"NL bank code", "NL", "ABNANL2A", "", "0417164300", "NL91ABNA0417164300", ""},
+		{"IE bank code and sort code", "IE", "AIBKIE2D", "931152", "12345678", "IE29AIBK93115212345678", ""},
+		{"DE account number is zero padded", "DE", "", "37040044", "532013000", "DE89370400440532013000", ""},
+		{"FR RIB key is computed", "FR", "", "2004101005", "500013M026", "FR1420041010050500013M02606", ""},
+		{"FR RIB key is kept", "FR", "", "2004101005", "500013M02606", "FR1420041010050500013M02606", ""},
+		{"FR 11 character account", "FR", "", "3000600001", "12345678901", "FR7630006000011234567890189", ""},
+		{"ES CCC is computed", "ES", "", "21000418", "0200051332", "ES9121000418450200051332", ""},
+		{"IT CIN is computed", "IT", "", "0542811101", "000000123456", "IT60X0542811101000000123456", ""},
+		{"IT CIN is kept", "IT", "", "X0542811101", "000000123456", "IT60X0542811101000000123456", ""},
+		{"BE check is computed", "BE", "", "539", "0075470", "BE68539007547034", ""},
+		{"PT NIB is computed", "PT", "", "00020123", "12345678901", "PT50000201231234567890154", ""},
+		{"CH", "CH", "", "00762", "011623852957", "CH9300762011623852957", ""},
+		{"PL", "PL", "", "10901014", "0000071219812874", "PL61109010140000071219812874", ""},
+		{"GR", "GR", "", "0110125", "0000000012300695", "GR1601101250000000012300695", ""},
+		{"LU", "LU", "", "001", "9400644750000", "LU280019400644750000", ""},
+		{"US does not issue IBANs", "US", "", "021000021", "12345678", "", IbanCountry},
+		{"GB without BIC", "GB", "", "123456", "98765432", "", IbanBBAN},
+		{"GB with digits for a bank code", "GB", "1234GB22", "123456", "98765432", "", IbanBBAN},
+	}
+
+	for _, i := range ibans {
+		t.Run(i.scenario, func(t *testing.T) {
+			iban, err := BuildIBAN(i.country, i.bic, i.bankId, i.accountNumber)
+
+			if i.rule == "" {
+				if err != nil || iban != i.iban {
+					t.Errorf("expected %q but got %q, %v", i.iban, iban, err)
+				}
+				return
+			}
+
+			var invalidIban *InvalidIBAN
+			if !errors.As(err, &invalidIban) || invalidIban.Rule != i.rule {
+				t.Errorf("expected rule %q but got %q, %v", i.rule, iban, err)
+			}
+		})
+	}
+}
+
+func TestRejectsIban(t *testing.T) {
+	for country := range BankIdCodes {
+		expected := country == "AU" || country == "CA" || country == "US"
+		if rejected := rejectsIban(country); rejected != expected {
+			t.Errorf("expected rejectsIban(%q) to be %t", country, expected)
+		}
+	}
+}
+
+func TestCreateWithDerivedIban(t *testing.T) {
+	var sent Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	client := testClient(server, 0)
+
+	builder := client.Create().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+		WithAccountClassification("Personal").
+		WithBankIdCode("GBDSC").
+		WithBic("WESTGB22").
+		WithBankId("123456").
+		WithAccountNumber("98765432").
+		WithDerivedIban()
+
+	if _, err := builder.WithCountry("GB").Do(context.Background()); err != nil {
+		t.Fatalf("create failed with %v", err)
+	}
+	if sent.Data.Attributes.Iban != "GB82WEST12345698765432" {
+		t.Errorf("expected the derived iban to be sent, got %q", sent.Data.Attributes.Iban)
+	}
+
+	_, err := builder.WithCountry("US").WithBankIdCode("USABA").WithBankId("021000021").Do(context.Background())
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("expected validation errors but got %v", err)
+	}
+
+	for _, err := range validationErrors {
+		if validationRule(err) == "derivedIban" {
+			return
+		}
+	}
+	t.Errorf("expected a derivedIban validation error, got %v", err)
+}
+
+func TestParseIBAN(t *testing.T) {
+	ibans := []struct {
+		iban       IBAN
+		components IBANComponents
+	}{
+		{"GB82WEST12345698765432", IBANComponents{"GB", "82", "WEST", "123456", "98765432", ""}},
+		{"NL91ABNA0417164300", IBANComponents{"NL", "91", "ABNA", "", "0417164300", ""}},
+		{"DE89370400440532013000", IBANComponents{"DE", "89", "37040044", "", "0532013000", ""}},
+		{"FR1420041010050500013M02606", IBANComponents{"FR", "14", "20041", "01005", "0500013M026", "06"}},
+		{"ES9121000418450200051332", IBANComponents{"ES", "91", "2100", "0418", "0200051332", "45"}},
+		{"IT60X0542811101000000123456", IBANComponents{"IT", "60", "05428", "11101", "000000123456", "X"}},
+		{"BE68539007547034", IBANComponents{"BE", "68", "539", "", "0075470", "34"}},
+		{"PT50000201231234567890154", IBANComponents{"PT", "50", "0002", "0123", "12345678901", "54"}},
+		{"NO9386011117947", IBANComponents{"NO", "93", "", "", "86011117947", ""}},
+	}
+
+	for _, i := range ibans {
+		t.Run(string(i.iban), func(t *testing.T) {
+			components, err := ParseIBAN(i.iban)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", i.iban, err)
+			}
+			if *components != i.components {
+				t.Errorf("expected %+v but got %+v", i.components, *components)
+			}
+		})
+	}
+
+	var invalidIban *InvalidIBAN
+	if _, err := ParseIBAN("GB83WEST12345698765432"); !errors.As(err, &invalidIban) {
+		t.Errorf("expected an invalid iban to be rejected, got %v", err)
+	}
+}
+
+func TestBbanLayoutsFitRegistry(t *testing.T) {
+	for country, layout := range bbanLayouts {
+		covered := 0
+		for _, s := range []span{layout.bank, layout.branch, layout.account, layout.check} {
+			covered += s.end - s.start
+		}
+
+		if bban := ibanRegistry[country].length - 4; covered != bban {
+			t.Errorf("%s: layout covers %d of the %d BBAN characters", country, covered, bban)
+		}
+	}
+}
+
+func TestFromIbanRoundTrip(t *testing.T) {
+	ibans := []struct {
+		iban          IBAN
+		bankId        BankId
+		accountNumber string
+	}{
+		{"GB82WEST12345698765432", "123456", "98765432"},
+		{"NL91ABNA0417164300", "", "0417164300"},
+		{"FR1420041010050500013M02606", "2004101005", "0500013M02606"},
+		{"FR7630006000011234567890189", "3000600001", "1234567890189"},
+		{"ES9121000418450200051332", "21000418", "450200051332"},
+		{"IT60X0542811101000000123456", "X0542811101", "000000123456"},
+		{"BE68539007547034", "539", "007547034"},
+		{"PT50000201231234567890154", "00020123", "1234567890154"},
+		{"CH9300762011623852957", "00762", "011623852957"},
+	}
+
+	for _, i := range ibans {
+		t.Run(string(i.iban), func(t *testing.T) {
+			ab := newAccountBuilder(nil).FromIban(i.iban).(createBuilder)
+			if ab.Country != Country(i.iban[:2]) || ab.BankId != i.bankId || ab.AccountNumber != i.accountNumber ||
+				ab.Iban != i.iban {
+				t.Fatalf("unexpected details %q %q %q %q", ab.Country, ab.BankId, ab.AccountNumber, ab.Iban)
+			}
+
+			bic := SwiftCode(i.iban[4:8]) + SwiftCode(i.iban[:2]) + "22"
+			if iban, err := BuildIBAN(ab.Country, bic, ab.BankId, ab.AccountNumber); err != nil || iban != i.iban {
+				t.Errorf("expected %q to be rebuilt but got %q, %v", i.iban, iban, err)
+			}
+		})
+	}
+
+	ab := newAccountBuilder(nil).FromIban("GB83WEST12345698765432").(createBuilder)
+	if ab.Country != "" || ab.Iban != "GB83WEST12345698765432" {
+		t.Errorf("invalid iban should only be kept for validation, got %+v", ab.AccountAttributes)
+	}
+}
+
+func TestBuildIbanRoundTripsThroughValidation(t *testing.T) {
+	accounts := []struct {
+		country       Country
+		bankId        BankId
+		accountNumber string
+		bic           SwiftCode
+	}{
+		{"BE", "539", "0075470", ""},
+		{"CH", "00762", "011623852957", ""},
+		{"DE", "37040044", "5320130", ""},
+		{"ES", "21000418", "0200051332", ""},
+		{"FR", "3000600001", "12345678901", ""},
+		{"GB", "089999", "66374958", "WESTGB22"},
+		{"GR", "0110125", "0000000012300695", ""},
+		{"IE", "931152", "12345678", "AIBKIE2D"},
+		{"IT", "0542811101", "000000123456", ""},
+		{"LU", "001", "9400644750000", ""},
+		{"NL", "", "0417164300", "ABNANL2A"},
+		{"PL", "10901014", "0000071219812874", ""},
+		{"PT", "00020123", "12345678901", ""},
+	}
+
+	for _, a := range accounts {
+		t.Run(string(a.country), func(t *testing.T) {
+			iban, err := BuildIBAN(a.country, a.bic, a.bankId, a.accountNumber)
+			if err != nil {
+				t.Fatalf("failed to build the iban: %v", err)
+			}
+
+			errs := make(chan []error, 1)
+			SetupF3Client(F3Env{}).Create().
+				FromIban(iban).
+				WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+				WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+				WithAccountClassification("Personal").
+				WithBankIdCode(BankIdCodes[a.country]).
+				WithBic(a.bic).
+				Validate(errs)
+			if err := <-errs; len(err) > 0 {
+				t.Errorf("%s parsed from %s failed validation: %v", a.country, iban, err)
+			}
+		})
+	}
+
+	if len(accounts) != len(bbanLayouts) {
+		t.Errorf("expected a round trip for each of the %d bban layouts", len(bbanLayouts))
+	}
+}
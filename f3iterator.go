@@ -0,0 +1,112 @@
+package form3
+
+import (
+	"context"
+)
+
+// AccountIterator walks every account returned by a list request, following the next links until the last
+// page. The following page is fetched in the background while the current one is being consumed.
+//
+//	it := client.List().All(ctx)
+//	defer it.Close()
+//	for it.Next() {
+//		account := it.Account()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type AccountIterator struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pages   chan pageResult
+	page    []Data
+	index   int
+	current Data
+	err     error
+	done    bool
+}
+
+type pageResult struct {
+	payload *PaginatedPayload
+	err     error
+}
+
+func (l listBuilder) All(ctx context.Context) *AccountIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &AccountIterator{
+		ctx:    ctx,
+		cancel: cancel,
+		pages:  make(chan pageResult, 1),
+	}
+
+	go it.prefetch(l)
+	return it
+}
+
+func (it *AccountIterator) prefetch(l listBuilder) {
+	defer close(it.pages)
+
+	payload, paginator, err := l.Do(it.ctx)
+	for {
+		select {
+		case it.pages <- pageResult{payload: payload, err: err}:
+		case <-it.ctx.Done():
+			return
+		}
+
+		if err != nil || payload.Links.Next == "" || len(payload.Data) == 0 {
+			return
+		}
+
+		payload, paginator, err = paginator.NextPage(it.ctx)
+	}
+}
+
+func (it *AccountIterator) Next() bool {
+	for it.index >= len(it.page) {
+		if it.err != nil || it.done {
+			return false
+		}
+
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		select {
+		case res, ok := <-it.pages:
+			switch {
+			case !ok:
+				it.done = true
+				it.cancel()
+				return false
+			case res.err != nil:
+				it.err = res.err
+				it.cancel()
+				return false
+			}
+			it.page = res.payload.Data
+			it.index = 0
+		case <-it.ctx.Done():
+			it.err = it.ctx.Err()
+			return false
+		}
+	}
+
+	it.current = it.page[it.index]
+	it.index++
+	return true
+}
+
+func (it *AccountIterator) Account() Data {
+	return it.current
+}
+
+func (it *AccountIterator) Err() error {
+	return it.err
+}
+
+// Close stops the background prefetching. It is safe to call Close once the iterator has been exhausted.
+func (it *AccountIterator) Close() {
+	it.done = true
+	it.cancel()
+}
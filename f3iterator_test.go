@@ -0,0 +1,98 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func pagedServer(pages int, failPage int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+		if page == failPage {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		next := ""
+		if page < pages-1 {
+			next = fmt.Sprintf("/v1/organisation/accounts?page%%5Bnumber%%5D=%d&page%%5Bsize%%5D=2", page+1)
+		}
+		_, _ = fmt.Fprintf(w, `{"data":[{"id":"%d-0"},{"id":"%d-1"}],"links":{"next":%q}}`, page, page, next)
+	}))
+}
+
+func TestAccountIterator(t *testing.T) {
+	server := pagedServer(3, -1)
+	defer server.Close()
+
+	it := testClient(server, 0).List().WithPageSize(2).All(context.Background())
+	defer it.Close()
+
+	var ids []UUID
+	for it.Next() {
+		ids = append(ids, it.Account().Id)
+	}
+
+	if err := it.Err(); err != nil {
+		t.Errorf("iterator failed with %q", err)
+	}
+
+	if fmt.Sprint(ids) != "[0-0 0-1 1-0 1-1 2-0 2-1]" {
+		t.Errorf("iterator did not walk every account, visited %v", ids)
+	}
+
+	if it.Next() {
+		t.Errorf("exhausted iterator should not return any more accounts")
+	}
+}
+
+func TestAccountIteratorError(t *testing.T) {
+	server := pagedServer(3, 1)
+	defer server.Close()
+
+	it := testClient(server, 0).List().WithPageSize(2).All(context.Background())
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("expected the accounts of the first page before failing but got %d", count)
+	}
+
+	if !errors.Is(it.Err(), F3StatusInternalServerError) {
+		t.Errorf("expected internal server error but got %v", it.Err())
+	}
+}
+
+func TestAccountIteratorCancellation(t *testing.T) {
+	server := pagedServer(1000, -1)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := testClient(server, 0).List().WithPageSize(2).All(ctx)
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		if count++; count == 3 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("expected context cancelled error but got %v", it.Err())
+	}
+
+	if count > 4 {
+		t.Errorf("iterator continued after cancellation, returned %d accounts", count)
+	}
+}
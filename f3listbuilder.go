@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 type listBuilder struct {
@@ -17,9 +19,18 @@ type listBuilder struct {
 type ListBuilder interface {
 	WithPage(page int) ListBuilder
 	WithPageSize(pageSize int) ListBuilder
+	WithBankIdFilter(bankId BankId) ListBuilder
+	WithBankIdCodeFilter(bankIdCode string) ListBuilder
+	WithAccountNumberFilter(accountNumber string) ListBuilder
+	WithIbanFilter(iban IBAN) ListBuilder
+	WithCustomerIdFilter(customerId string) ListBuilder
+	WithCountryFilter(country Country) ListBuilder
 	Validate(errors chan<- []error) ListBuilder
 	UnsafeRequest(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator
 	Request(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator
+	UnsafeDo(ctx context.Context) (*PaginatedPayload, Paginator, error)
+	Do(ctx context.Context) (*PaginatedPayload, Paginator, error)
+	All(ctx context.Context) *AccountIterator
 }
 
 type Paginator interface {
@@ -27,6 +38,10 @@ type Paginator interface {
 	Prev(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator
 	First(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator
 	Last(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator
+	NextPage(ctx context.Context) (*PaginatedPayload, Paginator, error)
+	PrevPage(ctx context.Context) (*PaginatedPayload, Paginator, error)
+	FirstPage(ctx context.Context) (*PaginatedPayload, Paginator, error)
+	LastPage(ctx context.Context) (*PaginatedPayload, Paginator, error)
 }
 
 func newListBuilder(client *F3Client) ListBuilder {
@@ -47,95 +62,193 @@ func (l listBuilder) WithPageSize(pageSize int) ListBuilder {
 	return l
 }
 
+func (l listBuilder) WithBankIdFilter(bankId BankId) ListBuilder {
+	l.BankId = bankId
+	return l
+}
+
+func (l listBuilder) WithBankIdCodeFilter(bankIdCode string) ListBuilder {
+	l.BankIdCode = bankIdCode
+	return l
+}
+
+func (l listBuilder) WithAccountNumberFilter(accountNumber string) ListBuilder {
+	l.AccountNumber = accountNumber
+	return l
+}
+
+func (l listBuilder) WithIbanFilter(iban IBAN) ListBuilder {
+	l.Iban = iban
+	return l
+}
+
+func (l listBuilder) WithCustomerIdFilter(customerId string) ListBuilder {
+	l.CustomerId = customerId
+	return l
+}
+
+func (l listBuilder) WithCountryFilter(country Country) ListBuilder {
+	l.Country = country
+	return l
+}
+
 func (l listBuilder) Validate(errors chan<- []error) ListBuilder {
 	if err := l.validate(); len(err) > 0 {
 		errors <- err
-		close(errors)
-		return l
 	}
-
+	close(errors)
 	return l
 }
 
 func (l listBuilder) UnsafeRequest(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
-	url := fmt.Sprintf("http://%s/v1/organisation/accounts?page%%5Bnumber%%5D=%d&page%%5Bsize%%5D]=%d", l.client.Env.F3BaseURL, l.Page, l.PageSize)
-	return l.internalRequest(url, ctx, response, errors)
+	return logPaginated(l, l.UnsafeDo)(ctx, response, errors)
 }
 
 func (l listBuilder) Request(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
-	if err := l.validate(); len(err) > 0 {
-		errors <- err
-		close(errors)
-		return l
-	} else {
-		url := fmt.Sprintf("http://%s/v1/organisation/accounts?page%%5Bnumber%%5D=%d&page%%5Bsize%%5D]=%d", l.client.Env.F3BaseURL, l.Page, l.PageSize)
-		Logger.Printf("URL: %s", url)
-		return l.internalRequest(url, ctx, response, errors)
-	}
+	return logPaginated(l, l.Do)(ctx, response, errors)
 }
 
 func (l listBuilder) Next(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
-	if err := l.canPaginate(); err != nil {
-		logPaginatedError(err, response, errors)
-		return l
-	}
+	return logPaginated(l, l.NextPage)(ctx, response, errors)
+}
+
+func (l listBuilder) Prev(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
+	return logPaginated(l, l.PrevPage)(ctx, response, errors)
+}
+
+func (l listBuilder) First(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
+	return logPaginated(l, l.FirstPage)(ctx, response, errors)
+}
+
+func (l listBuilder) Last(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
+	return logPaginated(l, l.LastPage)(ctx, response, errors)
+}
+
+func (l listBuilder) UnsafeDo(ctx context.Context) (payload *PaginatedPayload, paginator Paginator, err error) {
+	ctx, span := l.startSpan(ctx, "form3.list", l.Page)
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(F("f3.validation", "skipped"))
+
+	return l.unsafeDo(ctx)
+}
+
+func (l listBuilder) unsafeDo(ctx context.Context) (*PaginatedPayload, Paginator, error) {
+	query := l.filters()
+	query.Set("page[number]", strconv.Itoa(l.Page))
+	query.Set("page[size]", strconv.Itoa(l.PageSize))
+
+	url := l.client.resourceURL(fmt.Sprintf("/v1/organisation/accounts?%s", query.Encode()))
+	return l.internalRequest(url, ctx)
+}
+
+func (l listBuilder) Do(ctx context.Context) (payload *PaginatedPayload, paginator Paginator, err error) {
+	ctx, span := l.startSpan(ctx, "form3.list", l.Page)
+	defer func() { endSpan(span, err) }()
 
-	if l.response.Links.Next == "" {
-		logPaginatedError(fmt.Errorf("response is missing next link, which is required for traversal"), response, errors)
-		return l
+	errs := l.validate()
+	setValidationOutcome(span, errs)
+	if len(errs) > 0 {
+		return nil, l, ValidationErrors(errs)
 	}
 
-	url := fmt.Sprintf("http://%s%s", l.client.Env.F3BaseURL, l.response.Links.Next)
-	l.internalRequest(url, ctx, response, errors)
-	return l
+	return l.unsafeDo(ctx)
 }
 
-func (l listBuilder) Prev(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
+func (l listBuilder) startSpan(ctx context.Context, name string, page int) (context.Context, Span) {
+	return l.client.startSpan(ctx, name, F("f3.operation", OperationList), F("f3.page_number", page),
+		F("f3.page_size", l.PageSize))
+}
+
+func (l listBuilder) NextPage(ctx context.Context) (*PaginatedPayload, Paginator, error) {
+	return l.navigate(ctx, "next", func(links Links) string { return links.Next })
+}
+
+func (l listBuilder) PrevPage(ctx context.Context) (*PaginatedPayload, Paginator, error) {
+	return l.navigate(ctx, "prev", func(links Links) string { return links.Prev })
+}
+
+func (l listBuilder) FirstPage(ctx context.Context) (*PaginatedPayload, Paginator, error) {
+	return l.navigate(ctx, "first", func(links Links) string { return links.First })
+}
+
+func (l listBuilder) LastPage(ctx context.Context) (*PaginatedPayload, Paginator, error) {
+	return l.navigate(ctx, "last", func(links Links) string { return links.Last })
+}
+
+func (l listBuilder) navigate(ctx context.Context, name string, link func(Links) string) (payload *PaginatedPayload, paginator Paginator, err error) {
 	if err := l.canPaginate(); err != nil {
-		logPaginatedError(err, response, errors)
-		return l
+		return nil, l, err
 	}
 
-	if l.response.Links.Prev == "" {
-		logPaginatedError(fmt.Errorf("response is missing next previous, which is required for traversal"), response, errors)
-		return l
+	path := link(l.response.Links)
+	ctx, span := l.startSpan(ctx, "form3.list."+name, pageNumber(path))
+	defer func() { endSpan(span, err) }()
+
+	if path == "" {
+		return nil, l, fmt.Errorf("response is missing %s link, which is required for traversal", name)
 	}
 
-	url := fmt.Sprintf("http://%s%s", l.client.Env.F3BaseURL, l.response.Links.Prev)
-	l.internalRequest(url, ctx, response, errors)
-	return l
+	path, err = l.withFilters(path)
+	if err != nil {
+		return nil, l, fmt.Errorf("corrupted %s link %q - error: %w", name, link(l.response.Links), err)
+	}
+
+	url := l.client.linkURL(path)
+	return l.internalRequest(url, ctx)
 }
 
-func (l listBuilder) First(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
-	if err := l.canPaginate(); err != nil {
-		logPaginatedError(err, response, errors)
-		return l
+// pageNumber reads page[number] from a pagination link, returning -1 when it is missing.
+func pageNumber(link string) int {
+	u, err := url.Parse(link)
+	if err != nil {
+		return -1
+	}
+
+	page, err := strconv.Atoi(u.Query().Get("page[number]"))
+	if err != nil {
+		return -1
 	}
+	return page
+}
 
-	if l.response.Links.First == "" {
-		logPaginatedError(fmt.Errorf("response is missing next first, which is required for traversal"), response, errors)
-		return l
+func (l listBuilder) filters() url.Values {
+	filters := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			filters.Set(fmt.Sprintf("filter[%s]", key), value)
+		}
 	}
 
-	url := fmt.Sprintf("http://%s%s", l.client.Env.F3BaseURL, l.response.Links.First)
-	l.internalRequest(url, ctx, response, errors)
-	return l
+	set("bank_id", string(l.BankId))
+	set("bank_id_code", l.BankIdCode)
+	set("account_number", l.AccountNumber)
+	set("iban", string(l.Iban))
+	set("customer_id", l.CustomerId)
+	set("country", string(l.Country))
+	return filters
 }
 
-func (l listBuilder) Last(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
-	if err := l.canPaginate(); err != nil {
-		logPaginatedError(err, response, errors)
-		return l
+// withFilters makes sure the filters of the original request survive page traversal, even when the links
+// returned by the api drop them.
+func (l listBuilder) withFilters(link string) (string, error) {
+	filters := l.filters()
+	if len(filters) == 0 {
+		return link, nil
 	}
 
-	if l.response.Links.Last == "" {
-		logPaginatedError(fmt.Errorf("response is missing last link, which is required for traversal"), response, errors)
-		return l
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
 	}
 
-	url := fmt.Sprintf("http://%s%s", l.client.Env.F3BaseURL, l.response.Links.Last)
-	l.internalRequest(url, ctx, response, errors)
-	return l
+	query := u.Query()
+	for key, values := range filters {
+		if query.Get(key) == "" {
+			query[key] = values
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
 }
 
 func (l listBuilder) canPaginate() error {
@@ -147,44 +260,71 @@ func (l listBuilder) canPaginate() error {
 
 func (l listBuilder) validate() (errors []error) {
 	if l.client == nil {
-		errors = append(errors, fmt.Errorf("F3Client not set"))
+		errors = append(errors, ruleError("client", fmt.Errorf("F3Client not set")))
 	}
 
 	if l.Page < 0 {
-		errors = append(errors, fmt.Errorf("page requested cannot be smaller then 0"))
+		errors = append(errors, ruleError("page", fmt.Errorf("page requested cannot be smaller then 0")))
 	}
 
 	if l.PageSize < 1 {
-		errors = append(errors, fmt.Errorf("page size cannot be smaller then 1"))
+		errors = append(errors, ruleError("pageSize", fmt.Errorf("page size cannot be smaller then 1")))
+	}
+
+	if err := l.BankId.IsValid(); !l.BankId.IsZeroValue() && err != nil {
+		errors = append(errors, ruleError("bankIdFilter", fmt.Errorf("invalid bank id filter. %w", err)))
 	}
 
+	if err := l.Iban.IsValid(); !l.Iban.IsZeroValue() && err != nil {
+		errors = append(errors, ruleError("ibanFilter", fmt.Errorf("invalid iban filter. %w", err)))
+	}
+
+	if err := l.Country.IsValid(); !l.Country.IsZeroValue() && err != nil {
+		errors = append(errors, ruleError("countryFilter", fmt.Errorf("invalid country filter. %w", err)))
+	}
+
+	l.client.observeValidation(OperationList, errors)
 	return errors
 }
 
-func (l listBuilder) internalRequest(url string, ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) listBuilder {
+func (l listBuilder) internalRequest(url string, ctx context.Context) (*PaginatedPayload, Paginator, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		Logger.Printf("failed to fetch request for %q", url)
-		logPaginatedError(fmt.Errorf("error creating request Method: 'GET' Url: %q - error: %w", url, err), response, errors)
-		return l
+		l.client.log().Log(LevelError, "failed to create new http request", F("url", url), F("error", err))
+		return nil, l, fmt.Errorf("error creating request Method: 'GET' Url: %q - error: %w", redact(url), redactError(err))
 	}
 
-	req = req.WithContext(ctx)
+	req = req.WithContext(withOperation(ctx, OperationList))
 	res := &PaginatedPayload{}
 	if err := l.client.request(req, res); err != nil {
-		Logger.Printf("error requesting GET %q", url)
-		logPaginatedError(err, response, errors)
-		return l
+		l.client.log().Log(LevelError, "error requesting GET", F("url", url), F("error", err))
+		return nil, l, err
 	}
 	l.response = res
 
-	logPaginatedResponse(res, response, errors)
-	return l
+	return res, l, nil
+}
+
+type paginatedRequest func(ctx context.Context) (*PaginatedPayload, Paginator, error)
+
+// logPaginated adapts a blocking list request to the channel api. The request runs synchronously so that the
+// returned Paginator carries the context of the page that was fetched.
+func logPaginated(l listBuilder, request paginatedRequest) func(context.Context, chan<- *PaginatedPayload, chan<- []error) Paginator {
+	return func(ctx context.Context, response chan<- *PaginatedPayload, errors chan<- []error) Paginator {
+		payload, paginator, err := request(ctx)
+		if err != nil {
+			logPaginatedErrors(errorSlice(err), response, errors)
+			return l
+		}
+
+		logPaginatedResponse(payload, response, errors)
+		return paginator
+	}
 }
 
-func logPaginatedError(err error, response chan<- *PaginatedPayload, errors chan<- []error) {
+func logPaginatedErrors(err []error, response chan<- *PaginatedPayload, errors chan<- []error) {
 	close(response)
-	errors <- []error{err}
+	errors <- err
 	close(errors)
 }
 
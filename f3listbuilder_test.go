@@ -0,0 +1,102 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestListFilterValidation(t *testing.T) {
+	filters := []struct {
+		scenario    string
+		builder     ListBuilder
+		expectError bool
+	}{
+		{"Valid Filters", newListBuilder(&F3Client{}).
+			WithBankIdFilter("123456").
+			WithBankIdCodeFilter("GBDSC").
+			WithAccountNumberFilter("98765432").
+			WithIbanFilter("GB82WEST12345698765432").
+			WithCustomerIdFilter("customer").
+			WithCountryFilter("GB"), false},
+		{"Invalid Bank Id", newListBuilder(&F3Client{}).WithBankIdFilter("012345678901"), true},
+		{"Invalid IBAN", newListBuilder(&F3Client{}).WithIbanFilter("GB11"), true},
+		{"Invalid Country", newListBuilder(&F3Client{}).WithCountryFilter("XX"), true},
+	}
+
+	for _, f := range filters {
+		t.Run(f.scenario, func(t *testing.T) {
+			errs := make(chan []error, 1)
+			f.builder.Validate(errs)
+			err := <-errs
+
+			switch f.expectError {
+			case true:
+				if len(err) == 0 {
+					t.Errorf("validation did not fail when it was expected too!")
+				}
+			case false:
+				if len(err) > 0 {
+					t.Errorf("validation failed with %v on valid filters", err)
+				}
+			}
+		})
+	}
+
+	var invalidCountry *InvalidCountry
+	_, _, err := newListBuilder(&F3Client{}).WithCountryFilter("XX").Do(context.Background())
+	if !errors.As(err, &invalidCountry) {
+		t.Errorf("expected invalid country error but got %v", err)
+	}
+}
+
+func TestListFiltersCarryOverNavigation(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		_, _ = w.Write([]byte(`{"data":[],"links":{
+			"next":"/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=10",
+			"last":"/v1/organisation/accounts?page%5Bnumber%5D=9&page%5Bsize%5D=10&filter%5Bcountry%5D=FR"}}`))
+	}))
+	defer server.Close()
+
+	_, paginator, err := testClient(server, 0).List().
+		WithPageSize(10).
+		WithIbanFilter("GB82WEST12345698765432").
+		WithCountryFilter("GB").
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("list failed with %q", err)
+	}
+
+	if _, _, err = paginator.NextPage(context.Background()); err != nil {
+		t.Fatalf("navigating to the next page failed with %q", err)
+	}
+
+	if _, _, err = paginator.LastPage(context.Background()); err != nil {
+		t.Fatalf("navigating to the last page failed with %q", err)
+	}
+
+	expected := []struct {
+		page    string
+		country string
+	}{
+		{"0", "GB"},
+		{"1", "GB"},
+		{"9", "FR"},
+	}
+
+	for i, e := range expected {
+		query := queries[i]
+		if query.Get("page[number]") != e.page || query.Get("page[size]") != "10" {
+			t.Errorf("request %d has unexpected paging %v", i, query)
+		}
+
+		if query.Get("filter[iban]") != "GB82WEST12345698765432" || query.Get("filter[country]") != e.country {
+			t.Errorf("request %d has unexpected filters %v", i, query)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package form3
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// Field is a key value pair attached to a log message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// StructuredLogger receives every message logged by the package. Messages and fields pass through
+// NewRedactingLogger before reaching it, so implementations never see unmasked personal data.
+type StructuredLogger interface {
+	Log(level Level, msg string, fields ...Field)
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	min    Level
+}
+
+// NewStdLogger writes messages at min level and above to a standard library logger as logfmt style
+// key=value pairs.
+func NewStdLogger(logger *log.Logger, min Level) StructuredLogger {
+	return stdLogger{logger: logger, min: min}
+}
+
+func (l stdLogger) Log(level Level, msg string, fields ...Field) {
+	if level < l.min {
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "level=%s msg=%s", level, strconv.Quote(msg))
+	for _, field := range fields {
+		fmt.Fprintf(&sb, " %s=%s", field.Key, logValue(field.Value))
+	}
+	_ = l.logger.Output(2, sb.String())
+}
+
+func logValue(value interface{}) string {
+	s := fmt.Sprint(value)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+type nopLogger struct{}
+
+func NopLogger() StructuredLogger {
+	return nopLogger{}
+}
+
+func (nopLogger) Log(level Level, msg string, fields ...Field) {}
+
+type redactingLogger struct {
+	next StructuredLogger
+}
+
+// NewRedactingLogger masks ibans, account numbers, names, alternative names and customer ids in the message
+// and fields before passing them on to next.
+func NewRedactingLogger(next StructuredLogger) StructuredLogger {
+	if _, ok := next.(redactingLogger); ok {
+		return next
+	}
+	return redactingLogger{next: next}
+}
+
+func (l redactingLogger) Log(level Level, msg string, fields ...Field) {
+	redacted := make([]Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = Field{Key: field.Key, Value: redactField(field)}
+	}
+	l.next.Log(level, redact(msg), redacted...)
+}
+
+const redactedValue = "[REDACTED]"
+
+var sensitiveKeys = map[string]bool{
+	"iban":             true,
+	"accountnumber":    true,
+	"name":             true,
+	"alternativenames": true,
+	"customerid":       true,
+}
+
+var redactions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// filter[iban]=... query parameters, raw or percent encoded
+	{regexp.MustCompile(`(?i)(filter(?:\[|%5B)(?:iban|account_number|customer_id)(?:\]|%5D)=)[^&\s"']*`), "${1}" + redactedValue},
+	// json encoded attributes
+	{regexp.MustCompile(`("(?:iban|account_number|customer_id|name)"\s*:\s*)"(?:[^"\\]|\\.)*"`), `${1}"` + redactedValue + `"`},
+	{regexp.MustCompile(`("(?:name|alternative_names)"\s*:\s*)\[[^\]]*\]`), `${1}["` + redactedValue + `"]`},
+	// %+v formatted structs
+	{regexp.MustCompile(`\b((?:Iban|AccountNumber|CustomerId):)[^\s}]*`), "${1}" + redactedValue},
+	{regexp.MustCompile(`\b((?:Name|AlternativeNames):)\[[^\]]*\]`), "${1}[" + redactedValue + "]"},
+}
+
+var ibanPattern = regexp.MustCompile(`\b[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}\b`)
+
+// redact masks personal data in free text such as log messages and error strings. Ibans found anywhere keep
+// their country code and last four characters.
+func redact(s string) string {
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+
+	return ibanPattern.ReplaceAllStringFunc(s, maskIban)
+}
+
+// maskIban keeps the country code and last four characters of an iban.
+func maskIban(iban string) string {
+	return mask(iban, 2, 4)
+}
+
+// mask replaces every character but the first head and last tail characters with *, values too short to keep
+// anything are masked entirely.
+func mask(s string, head int, tail int) string {
+	if len(s) <= head+tail {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:head] + strings.Repeat("*", len(s)-head-tail) + s[len(s)-tail:]
+}
+
+func redactField(field Field) interface{} {
+	key := strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(field.Key))
+	if sensitiveKeys[key] {
+		return redactedValue
+	}
+
+	switch v := field.Value.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64, Level:
+		return v
+	case error:
+		return redact(v.Error())
+	}
+	return redact(fmt.Sprintf("%+v", field.Value))
+}
+
+// redactedError masks personal data in the message of an error it wraps, keeping the chain intact for
+// errors.Is and errors.As.
+type redactedError struct {
+	err error
+}
+
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err}
+}
+
+func (e *redactedError) Error() string {
+	return redact(e.err.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,93 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	redactions := []struct {
+		scenario string
+		text     string
+		expected string
+	}{
+		{"Iban", "account GB82WEST12345698765432 not found", "account GB****************5432 not found"},
+		{"Query Filters", "GET /v1/organisation/accounts?filter%5Biban%5D=GB82WEST12345698765432&filter[customer_id]=c-1&filter[country]=GB",
+			"GET /v1/organisation/accounts?filter%5Biban%5D=[REDACTED]&filter[customer_id]=[REDACTED]&filter[country]=GB"},
+		{"Json", `{"account_number":"41426819","name":["Jane Doe"],"alternative_names":["JD"],"customer_id":"c-1","country":"GB"}`,
+			`{"account_number":"[REDACTED]","name":["[REDACTED]"],"alternative_names":["[REDACTED]"],"customer_id":"[REDACTED]","country":"GB"}`},
+		{"Formatted Struct", fmt.Sprintf("%+v", AccountAttributes{Country: "GB", AccountNumber: "41426819",
+			Name: []Identifier{"Jane", "Doe"}, AlternativeNames: []Identifier{"JD"}, CustomerId: "c-1"}), ""},
+		{"Untouched", "GET /v1/organisation/accounts/81d62ace-23f2-4aff-a7d6-60d7674bc5bb", "GET /v1/organisation/accounts/81d62ace-23f2-4aff-a7d6-60d7674bc5bb"},
+	}
+
+	for _, r := range redactions {
+		t.Run(r.scenario, func(t *testing.T) {
+			redacted := redact(r.text)
+			if r.expected != "" && redacted != r.expected {
+				t.Errorf("expected %q but got %q", r.expected, redacted)
+			}
+
+			for _, secret := range []string{"GB82WEST12345698765432", "41426819", "Jane", "Doe", "JD", "c-1"} {
+				if strings.Contains(redacted, secret) {
+					t.Errorf("redacted text %q still contains %q", redacted, secret)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactingLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := NewRedactingLogger(NewStdLogger(log.New(&logs, "", 0), LevelInfo))
+
+	logger.Log(LevelDebug, "filtered out", F("iban", "GB82WEST12345698765432"))
+	logger.Log(LevelInfo, "created account GB82WEST12345698765432", F("Iban", "GB82WEST12345698765432"),
+		F("customer_id", "c-1"), F("error", errors.New("duplicate account 41426819 for {\"account_number\":\"41426819\"}")),
+		F("attempt", 2))
+
+	expected := `level=info msg="created account GB****************5432" Iban=[REDACTED] customer_id=[REDACTED] ` +
+		`error="duplicate account 41426819 for {\"account_number\":\"[REDACTED]\"}" attempt=2` + "\n"
+	if logs.String() != expected {
+		t.Errorf("expected log line %q but got %q", expected, logs.String())
+	}
+
+	NopLogger().Log(LevelError, "discarded")
+}
+
+func TestClientRedactsLogsAndErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_message":"no account with iban GB82WEST12345698765432"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	client, err := New(WithBaseURL(server.URL), WithMaxRetries(0),
+		WithLogger(NewStdLogger(log.New(&logs, "", 0), LevelDebug)),
+		WithMiddleware(LoggingMiddleware(NewStdLogger(log.New(&logs, "", 0), LevelDebug))))
+	if err != nil {
+		t.Fatalf("failed to create client %q", err)
+	}
+
+	_, _, err = client.List().WithIbanFilter("GB82WEST12345698765432").Do(context.Background())
+	if !errors.Is(err, F3StatusNotFound) {
+		t.Fatalf("expected a not found error but got %v", err)
+	}
+
+	if strings.Contains(err.Error(), "GB82WEST12345698765432") {
+		t.Errorf("error %q contains an unmasked iban", err)
+	}
+
+	if logs.Len() == 0 || strings.Contains(logs.String(), "GB82WEST12345698765432") {
+		t.Errorf("logs %q contain an unmasked iban", logs.String())
+	}
+}
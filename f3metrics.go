@@ -0,0 +1,233 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	OperationCreate = "create"
+	OperationFetch  = "fetch"
+	OperationList   = "list"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+
+	unknownOperation = "unknown"
+)
+
+// Metrics receives measurements from the client. ObserveRequest is called once per attempt with the status
+// code of the response, or 0 when no response was received.
+type Metrics interface {
+	ObserveRequest(operation string, statusCode int, duration time.Duration)
+	ObserveRetry(operation string)
+	ObserveValidationFailure(operation, rule string)
+}
+
+type operationKey struct{}
+
+func withOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+func operationFromContext(ctx context.Context) string {
+	if operation, ok := ctx.Value(operationKey{}).(string); ok {
+		return operation
+	}
+	return unknownOperation
+}
+
+func WithMetrics(metrics Metrics) Option {
+	return func(o *clientOptions) error {
+		o.metrics = metrics
+		return nil
+	}
+}
+
+func (c *F3Client) observeRequest(ctx context.Context, statusCode int, start time.Time) {
+	if c.Metrics != nil {
+		c.Metrics.ObserveRequest(operationFromContext(ctx), statusCode, time.Since(start))
+	}
+}
+
+func (c *F3Client) observeRetry(ctx context.Context) {
+	if c.Metrics != nil {
+		c.Metrics.ObserveRetry(operationFromContext(ctx))
+	}
+}
+
+// observeValidation counts each failed validation rule. It is safe to call on a nil client, as builders are
+// validated before checking a client has been set.
+func (c *F3Client) observeValidation(operation string, errs []error) {
+	if c == nil || c.Metrics == nil {
+		return
+	}
+
+	// a rule returning several errors is counted once
+	failed := map[string]bool{}
+	for _, err := range errs {
+		if rule := validationRule(err); !failed[rule] {
+			failed[rule] = true
+			c.Metrics.ObserveValidationFailure(operation, rule)
+		}
+	}
+}
+
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics implements Metrics, serving the collected metrics in the Prometheus text exposition format.
+type PrometheusMetrics struct {
+	mu          sync.Mutex
+	buckets     []float64
+	requests    map[[2]string]uint64
+	latencies   map[string]*histogram
+	retries     map[string]uint64
+	validations map[[2]string]uint64
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusMetrics creates metrics with the given latency histogram buckets in seconds, using
+// DefaultLatencyBuckets when none are given.
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &PrometheusMetrics{
+		buckets:     sorted,
+		requests:    map[[2]string]uint64{},
+		latencies:   map[string]*histogram{},
+		retries:     map[string]uint64{},
+		validations: map[[2]string]uint64{},
+	}
+}
+
+func (m *PrometheusMetrics) ObserveRequest(operation string, statusCode int, duration time.Duration) {
+	code := "error"
+	if statusCode > 0 {
+		code = strconv.Itoa(statusCode)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[[2]string{operation, code}]++
+
+	h, ok := m.latencies[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[operation] = h
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *PrometheusMetrics) ObserveRetry(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[operation]++
+}
+
+func (m *PrometheusMetrics) ObserveValidationFailure(operation, rule string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.validations[[2]string{operation, rule}]++
+}
+
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WriteText(w)
+}
+
+// WriteText writes every metric in the Prometheus text exposition format, sorted by label values.
+func (m *PrometheusMetrics) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+
+	writeHeader(&sb, "f3_client_requests_total", "counter", "Requests sent to the api by operation and status code.")
+	for _, key := range sortedPairs(m.requests) {
+		fmt.Fprintf(&sb, "f3_client_requests_total{operation=%s,code=%s} %d\n",
+			labelValue(key[0]), labelValue(key[1]), m.requests[key])
+	}
+
+	writeHeader(&sb, "f3_client_request_duration_seconds", "histogram", "Latency of requests sent to the api by operation.")
+	operations := make([]string, 0, len(m.latencies))
+	for operation := range m.latencies {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		h := m.latencies[operation]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&sb, "f3_client_request_duration_seconds_bucket{operation=%s,le=%q} %d\n",
+				labelValue(operation), strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(&sb, "f3_client_request_duration_seconds_bucket{operation=%s,le=\"+Inf\"} %d\n", labelValue(operation), h.count)
+		fmt.Fprintf(&sb, "f3_client_request_duration_seconds_sum{operation=%s} %s\n", labelValue(operation),
+			strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&sb, "f3_client_request_duration_seconds_count{operation=%s} %d\n", labelValue(operation), h.count)
+	}
+
+	writeHeader(&sb, "f3_client_retries_total", "counter", "Requests retried after a retryable failure by operation.")
+	retries := make([]string, 0, len(m.retries))
+	for operation := range m.retries {
+		retries = append(retries, operation)
+	}
+	sort.Strings(retries)
+	for _, operation := range retries {
+		fmt.Fprintf(&sb, "f3_client_retries_total{operation=%s} %d\n", labelValue(operation), m.retries[operation])
+	}
+
+	writeHeader(&sb, "f3_client_validation_failures_total", "counter", "Builders rejected by client side validation by operation and rule.")
+	for _, key := range sortedPairs(m.validations) {
+		fmt.Fprintf(&sb, "f3_client_validation_failures_total{operation=%s,rule=%s} %d\n",
+			labelValue(key[0]), labelValue(key[1]), m.validations[key])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeHeader(sb *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedPairs(m map[[2]string]uint64) [][2]string {
+	keys := make([][2]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
+
+func labelValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
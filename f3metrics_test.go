@@ -0,0 +1,111 @@
+package form3
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	var creates int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && atomic.AddInt32(&creates, 1) == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		case r.Method == "GET":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	metrics := NewPrometheusMetrics()
+	client := testClient(server, 2)
+	client.Metrics = metrics
+
+	_, err := client.Create().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+		WithCountry(Countries["GB"]).
+		WithBankId("000006").
+		WithBic("NWBKGB22").
+		WithBankIdCode("GBDSC").
+		WithAccountClassification("Personal").
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("create failed with %q", err)
+	}
+
+	_, _ = client.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+	_ = client.Delete().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+	_, _ = client.Create().WithCountry(Countries["GB"]).WithBankId("1").Do(context.Background())
+	_, _, _ = client.List().WithPageSize(0).Do(context.Background())
+
+	scraper := httptest.NewServer(metrics)
+	defer scraper.Close()
+
+	res, err := http.Get(scraper.URL)
+	if err != nil {
+		t.Fatalf("scraping metrics failed with %q", err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", res.Header.Get("Content-Type"))
+	}
+
+	expected := []string{
+		"# TYPE f3_client_requests_total counter",
+		`f3_client_requests_total{operation="create",code="201"} 1`,
+		`f3_client_requests_total{operation="create",code="503"} 1`,
+		`f3_client_requests_total{operation="delete",code="204"} 1`,
+		`f3_client_requests_total{operation="fetch",code="404"} 1`,
+		"# TYPE f3_client_request_duration_seconds histogram",
+		`f3_client_request_duration_seconds_bucket{operation="create",le="+Inf"} 2`,
+		`f3_client_request_duration_seconds_count{operation="fetch"} 1`,
+		`f3_client_retries_total{operation="create"} 1`,
+		`f3_client_validation_failures_total{operation="create",rule="bankIdCode"} 1`,
+		`f3_client_validation_failures_total{operation="create",rule="requiredFields"} 1`,
+		`f3_client_validation_failures_total{operation="list",rule="pageSize"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("expected scraped metrics to contain %q\n%s", line, body)
+		}
+	}
+}
+
+func TestPrometheusHistogramBuckets(t *testing.T) {
+	metrics := NewPrometheusMetrics(1, 0.1)
+	metrics.ObserveRequest(OperationList, 200, 50*time.Millisecond)
+	metrics.ObserveRequest(OperationList, 200, 500*time.Millisecond)
+	metrics.ObserveRequest(OperationList, 0, 2*time.Second)
+
+	var sb strings.Builder
+	if err := metrics.WriteText(&sb); err != nil {
+		t.Fatalf("writing metrics failed with %q", err)
+	}
+
+	expected := []string{
+		`f3_client_request_duration_seconds_bucket{operation="list",le="0.1"} 1`,
+		`f3_client_request_duration_seconds_bucket{operation="list",le="1"} 2`,
+		`f3_client_request_duration_seconds_bucket{operation="list",le="+Inf"} 3`,
+		`f3_client_request_duration_seconds_sum{operation="list"} 2.55`,
+		`f3_client_requests_total{operation="list",code="error"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(sb.String(), line+"\n") {
+			t.Errorf("expected metrics to contain %q\n%s", line, sb.String())
+		}
+	}
+}
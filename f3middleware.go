@@ -0,0 +1,139 @@
+package form3
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Doer sends a single http request, it is satisfied by *http.Client.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the Doer sending each attempt of a request. The request passed down the chain is a copy
+// owned by the attempt and may be modified before calling next, authentication and signing are applied after
+// every middleware has run. A middleware may also answer without calling next.
+type Middleware func(next Doer) Doer
+
+var ErrInjectedFault = errors.New("fault injected by middleware")
+
+// Use appends middlewares to the client. The first middleware registered is the outermost, seeing each request
+// first and its response last. Use is not safe to call while the client is sending requests.
+func (c *F3Client) Use(middlewares ...Middleware) *F3Client {
+	c.middlewares = append(c.middlewares, middlewares...)
+	return c
+}
+
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(o *clientOptions) error {
+		o.middlewares = append(o.middlewares, middlewares...)
+		return nil
+	}
+}
+
+func (c *F3Client) doer() Doer {
+	var doer Doer = DoerFunc(c.send)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		doer = c.middlewares[i](doer)
+	}
+	return doer
+}
+
+// HeaderMiddleware sets the given headers on every request, replacing any value already set.
+func HeaderMiddleware(header http.Header) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			for key, values := range header {
+				req.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// LoggingMiddleware logs the method, url, status and duration of every request at debug level, and failed
+// requests at warn level. A nil logger logs to Logger.
+func LoggingMiddleware(logger StructuredLogger) Middleware {
+	if logger == nil {
+		logger = Logger
+	}
+	logger = NewRedactingLogger(logger)
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Do(req)
+			if err != nil {
+				logger.Log(LevelWarn, "request failed", F("method", req.Method), F("url", req.URL),
+					F("duration", time.Since(start)), F("error", err))
+				return res, err
+			}
+
+			logger.Log(LevelDebug, "request completed", F("method", req.Method), F("url", req.URL),
+				F("status", res.StatusCode), F("duration", time.Since(start)))
+			return res, nil
+		})
+	}
+}
+
+// FaultInjection configures FaultInjectionMiddleware. With probability Rate a request is answered with
+// StatusCode without reaching the api, or fails with ErrInjectedFault when StatusCode is not set.
+type FaultInjection struct {
+	Rate       float64
+	StatusCode int
+	Delay      time.Duration
+	Source     rand.Source
+}
+
+// FaultInjectionMiddleware fails a share of requests to exercise retries and circuit breaking in tests.
+func FaultInjectionMiddleware(fault FaultInjection) Middleware {
+	source := fault.Source
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+	random := rand.New(source)
+	var mu sync.Mutex
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			inject := random.Float64() < fault.Rate
+			mu.Unlock()
+
+			if !inject {
+				return next.Do(req)
+			}
+
+			if err := sleep(req.Context(), fault.Delay); err != nil {
+				return nil, err
+			}
+
+			if fault.StatusCode == 0 {
+				return nil, fmt.Errorf("%s %s. %w", req.Method, req.URL, ErrInjectedFault)
+			}
+
+			return &http.Response{
+				Status:     fmt.Sprintf("%d %s", fault.StatusCode, http.StatusText(fault.StatusCode)),
+				StatusCode: fault.StatusCode,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		})
+	}
+}
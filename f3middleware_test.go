@@ -0,0 +1,174 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type staticAuthenticator string
+
+func (a staticAuthenticator) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+string(a))
+	return nil
+}
+
+func (a staticAuthenticator) Invalidate(req *http.Request) {}
+
+func TestMiddlewareOrdering(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				if req.Header.Get("Authorization") != "" {
+					t.Errorf("middleware %s saw the request after authentication", name)
+				}
+				res, err := next.Do(req)
+				calls = append(calls, name+" response")
+				return res, err
+			})
+		}
+	}
+
+	client := testClient(server, 0).Use(record("outer"), record("middle")).Use(record("inner"))
+	client.Authenticator = staticAuthenticator("token")
+	if err := client.Delete().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background()); err != nil {
+		t.Fatalf("delete request failed with %q", err)
+	}
+
+	expected := "outer request,middle request,inner request,inner response,middle response,outer response"
+	if strings.Join(calls, ",") != expected {
+		t.Errorf("expected middleware calls %q but got %q", expected, strings.Join(calls, ","))
+	}
+}
+
+func TestMiddlewareRunsPerAttempt(t *testing.T) {
+	var calls, attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := testClient(server, 3).Use(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&attempts, 1)
+			return next.Do(req)
+		})
+	})
+
+	if err := client.Delete().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background()); err != nil {
+		t.Fatalf("delete request failed with %q", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected the middleware to run for each of the 3 attempts but ran %d times", attempts)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request reached the server")
+	}))
+	defer server.Close()
+
+	cached := `{"data":{"id":"81d62ace-23f2-4aff-a7d6-60d7674bc5bb","type":"accounts"}}`
+	client := testClient(server, 0).Use(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(cached)),
+				Request:    req,
+			}, nil
+		})
+	})
+
+	payload, err := client.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+	if err != nil || payload.Data.Id != "81d62ace-23f2-4aff-a7d6-60d7674bc5bb" {
+		t.Errorf("expected the cached payload but got %+v, %v", payload, err)
+	}
+}
+
+func TestStandardMiddlewares(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Team")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	client, err := New(WithBaseURL(server.URL), WithMaxRetries(0), WithMiddleware(
+		LoggingMiddleware(NewStdLogger(log.New(&logs, "", 0), LevelDebug)),
+		HeaderMiddleware(http.Header{"x-team": []string{"payments"}}),
+	))
+	if err != nil {
+		t.Fatalf("failed to create client %q", err)
+	}
+
+	if err := client.Delete().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background()); err != nil {
+		t.Fatalf("delete request failed with %q", err)
+	}
+
+	if header != "payments" {
+		t.Errorf("expected the X-Team header to be injected but got %q", header)
+	}
+
+	if !strings.Contains(logs.String(), "DELETE") || !strings.Contains(logs.String(), "status=204") {
+		t.Errorf("expected the request to be logged but got %q", logs.String())
+	}
+}
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	faults := []struct {
+		scenario string
+		fault    FaultInjection
+		expected error
+	}{
+		{"Status Code", FaultInjection{Rate: 1, StatusCode: http.StatusServiceUnavailable}, F3StatusServiceUnavailable},
+		{"Transport Error", FaultInjection{Rate: 1}, ErrInjectedFault},
+		{"Never", FaultInjection{Rate: 0, StatusCode: http.StatusServiceUnavailable}, nil},
+	}
+
+	for _, f := range faults {
+		t.Run(f.scenario, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			f.fault.Source = rand.NewSource(1)
+			client := testClient(server, 2).Use(FaultInjectionMiddleware(f.fault))
+
+			err := client.Delete().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+			if !errors.Is(err, f.expected) {
+				t.Errorf("expected %v but got %v", f.expected, err)
+			}
+
+			if f.expected != nil && atomic.LoadInt32(&calls) != 0 {
+				t.Errorf("expected injected faults to never reach the server")
+			}
+		})
+	}
+}
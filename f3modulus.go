@@ -0,0 +1,343 @@
+package form3
+
+//go:generate go run ./scripts/genmodulus -weights data/valacdos.txt -substitutions data/scsubtab.txt -o f3modulus_data.go
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModulusMethod is the algorithm Vocalink assigns to a range of sort codes.
+type ModulusMethod string
+
+const (
+	ModulusMod10           ModulusMethod = "MOD10"
+	ModulusMod11           ModulusMethod = "MOD11"
+	ModulusDoubleAlternate ModulusMethod = "DBLAL"
+)
+
+// digit positions of a sort code and account number as named in the Vocalink specification, u-z being the sort
+// code and a-h the account number.
+const (
+	posA = 6
+	posB = 7
+	posC = 8
+	posG = 12
+	posH = 13
+)
+
+var (
+	exception2Weights    = [14]int{0, 0, 1, 2, 5, 3, 6, 4, 8, 7, 10, 9, 3, 1}
+	exception2G9Weights  = [14]int{0, 0, 0, 0, 0, 0, 0, 0, 8, 7, 10, 9, 3, 1}
+	exception8SortCode   = "090126"
+	exception9SortCode   = "309634"
+	modulusSortCodeRegex = regexp.MustCompile("^[0-9]{6}$")
+	modulusAccountRegex  = regexp.MustCompile("^[0-9]{8}$")
+	bundledModulusTable  = mustParseModulusTable(modulusWeightsData, modulusSubstitutionsData)
+)
+
+type InvalidUKAccount struct {
+	SortCode  string
+	Method    ModulusMethod
+	Exception int
+}
+
+func (e *InvalidUKAccount) Error() string {
+	if e.Exception > 0 {
+		return fmt.Sprintf("account number failed the %s modulus check (exception %d) for sort code %q", e.Method,
+			e.Exception, e.SortCode)
+	}
+	return fmt.Sprintf("account number failed the %s modulus check for sort code %q", e.Method, e.SortCode)
+}
+
+type modulusWeight struct {
+	start, end string
+	method     ModulusMethod
+	weights    [14]int
+	exception  int
+}
+
+type modulusTable struct {
+	weights       []modulusWeight
+	substitutions map[string]string
+}
+
+// WithModulusTable checks GB accounts against the contents of valacdos.txt and, optionally, scsubtab.txt instead
+// of the bundled Vocalink tables, so that a refreshed table can be picked up without rebuilding the client.
+func WithModulusTable(weights io.Reader, substitutions io.Reader) Option {
+	return func(o *clientOptions) error {
+		table, err := parseModulusTable(weights, substitutions)
+		if err != nil {
+			return fmt.Errorf("cannot load modulus table - error: %w", err)
+		}
+		o.modulusTable = table
+		return nil
+	}
+}
+
+func mustParseModulusTable(weights string, substitutions string) *modulusTable {
+	table, err := parseModulusTable(strings.NewReader(weights), strings.NewReader(substitutions))
+	if err != nil {
+		panic(fmt.Errorf("bundled modulus table is corrupted - error: %w", err))
+	}
+	return table
+}
+
+func parseModulusTable(weights io.Reader, substitutions io.Reader) (*modulusTable, error) {
+	table := &modulusTable{substitutions: map[string]string{}}
+
+	err := scanModulusLines(weights, func(line int, fields []string) error {
+		w, err := parseModulusWeight(fields)
+		if err != nil {
+			return fmt.Errorf("invalid modulus weight on line %d - error: %w", line, err)
+		}
+		table.weights = append(table.weights, w)
+		return nil
+	})
+	if err != nil || substitutions == nil {
+		return table, err
+	}
+
+	err = scanModulusLines(substitutions, func(line int, fields []string) error {
+		if len(fields) != 2 || !modulusSortCodeRegex.MatchString(fields[0]) ||
+			!modulusSortCodeRegex.MatchString(fields[1]) {
+			return fmt.Errorf("invalid sort code substitution on line %d: %q", line, strings.Join(fields, " "))
+		}
+		table.substitutions[fields[0]] = fields[1]
+		return nil
+	})
+	return table, err
+}
+
+// scanModulusLines feeds the whitespace separated fields of each line to f, skipping blank lines and # comments.
+func scanModulusLines(r io.Reader, f func(line int, fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if err := f(line, strings.Fields(text)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func parseModulusWeight(fields []string) (w modulusWeight, err error) {
+	if len(fields) != 17 && len(fields) != 18 {
+		return w, fmt.Errorf("expected 17 or 18 columns, found %d", len(fields))
+	}
+
+	w.start, w.end, w.method = fields[0], fields[1], ModulusMethod(fields[2])
+	if !modulusSortCodeRegex.MatchString(w.start) || !modulusSortCodeRegex.MatchString(w.end) {
+		return w, fmt.Errorf("invalid sort code range %q - %q", w.start, w.end)
+	}
+
+	switch w.method {
+	case ModulusMod10, ModulusMod11, ModulusDoubleAlternate:
+	default:
+		return w, fmt.Errorf("unknown modulus method %q", w.method)
+	}
+
+	for i := range w.weights {
+		if w.weights[i], err = strconv.Atoi(fields[3+i]); err != nil {
+			return w, fmt.Errorf("invalid weight %q - error: %w", fields[3+i], err)
+		}
+	}
+
+	if len(fields) == 18 {
+		if w.exception, err = strconv.Atoi(fields[17]); err != nil {
+			return w, fmt.Errorf("invalid exception %q - error: %w", fields[17], err)
+		}
+	}
+	return w, nil
+}
+
+// validateUKAccount runs the Vocalink modulus checks for a sort code and account number pair. Sort codes missing
+// from the weight table cannot be checked and are presumed valid, as the specification requires.
+func (t *modulusTable) validateUKAccount(sortCode string, accountNumber string) error {
+	if !modulusSortCodeRegex.MatchString(sortCode) || !modulusAccountRegex.MatchString(accountNumber) {
+		return nil
+	}
+	return t.validate(sortCode, accountNumber)
+}
+
+func (t *modulusTable) rows(sortCode string) (rows []modulusWeight) {
+	for _, w := range t.weights {
+		if sortCode >= w.start && sortCode <= w.end {
+			rows = append(rows, w)
+		}
+	}
+	return rows
+}
+
+func (t *modulusTable) validate(sortCode string, accountNumber string) error {
+	rows := t.rows(sortCode)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	d := modulusDigits(sortCode + accountNumber)
+	if isForeignCurrencyAccount(rows, d) {
+		return nil
+	}
+
+	fail := func(w modulusWeight) error {
+		return &InvalidUKAccount{SortCode: sortCode, Method: w.method, Exception: w.exception}
+	}
+
+	first := t.passes(rows[0], sortCode, accountNumber)
+	if len(rows) == 1 {
+		if !first {
+			return fail(rows[0])
+		}
+		return nil
+	}
+
+	second := rows[1]
+	switch rows[0].exception {
+	case 2, 10, 12:
+		// exceptions 2 & 9, 10 & 11 and 12 & 13 accept the account when either check passes
+		if first || t.passes(second, sortCode, accountNumber) {
+			return nil
+		}
+		return fail(rows[0])
+	}
+
+	if !first {
+		return fail(rows[0])
+	}
+
+	if second.exception == 3 && (d[posC] == 6 || d[posC] == 9) {
+		return nil
+	}
+
+	if !t.passes(second, sortCode, accountNumber) {
+		return fail(second)
+	}
+	return nil
+}
+
+// isForeignCurrencyAccount implements exception 6, foreign currency accounts cannot be checked.
+func isForeignCurrencyAccount(rows []modulusWeight, d [14]int) bool {
+	for _, w := range rows {
+		if w.exception == 6 && d[posA] >= 4 && d[posA] <= 8 && d[posG] == d[posH] {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *modulusTable) passes(w modulusWeight, sortCode string, accountNumber string) bool {
+	switch w.exception {
+	case 5:
+		if substitute, ok := t.substitutions[sortCode]; ok {
+			sortCode = substitute
+		}
+	case 8:
+		sortCode = exception8SortCode
+	case 9:
+		sortCode = exception9SortCode
+	}
+
+	d := modulusDigits(sortCode + accountNumber)
+	weights := w.weights
+	switch w.exception {
+	case 2:
+		if d[posA] != 0 && d[posG] != 9 {
+			weights = exception2Weights
+		} else if d[posA] != 0 {
+			weights = exception2G9Weights
+		}
+	case 7:
+		if d[posG] == 9 {
+			zeroiseSortCodeWeights(&weights)
+		}
+	case 10:
+		if ab := d[posA]*10 + d[posB]; (ab == 9 || ab == 99) && d[posG] == 9 {
+			zeroiseSortCodeWeights(&weights)
+		}
+	}
+
+	total := modulusTotal(w.method, d, weights)
+	switch w.method {
+	case ModulusDoubleAlternate:
+		if w.exception == 1 {
+			total += 27
+		}
+
+		if w.exception == 5 {
+			if r := total % 10; r != 0 {
+				return 10-r == d[posH]
+			}
+			return d[posH] == 0
+		}
+		return total%10 == 0
+	case ModulusMod10:
+		return total%10 == 0
+	default:
+		r := total % 11
+		switch w.exception {
+		case 4:
+			return r == d[posG]*10+d[posH]
+		case 5:
+			if r == 0 {
+				return d[posG] == 0
+			}
+			return r != 1 && 11-r == d[posG]
+		case 14:
+			if r == 0 {
+				return true
+			}
+			// the last digit of the account number may be a suffix, drop it and check the remaining seven
+			if h := d[posH]; h != 0 && h != 1 && h != 9 {
+				return false
+			}
+			shifted := modulusDigits(sortCode + "0" + accountNumber[:7])
+			return modulusTotal(w.method, shifted, weights)%11 == 0
+		}
+		return r == 0
+	}
+}
+
+func zeroiseSortCodeWeights(weights *[14]int) {
+	for i := 0; i <= posB; i++ {
+		weights[i] = 0
+	}
+}
+
+func modulusTotal(method ModulusMethod, d [14]int, weights [14]int) (total int) {
+	for i := range d {
+		product := d[i] * weights[i]
+		if method == ModulusDoubleAlternate {
+			product = product/10 + product%10
+		}
+		total += product
+	}
+	return total
+}
+
+func modulusDigits(s string) (d [14]int) {
+	for i := range d {
+		d[i] = int(s[i] - '0')
+	}
+	return d
+}
+
+func ukModulusValidator(ab createBuilder) (errors []error) {
+	table := bundledModulusTable
+	if ab.client != nil && ab.client.modulusTable != nil {
+		table = ab.client.modulusTable
+	}
+
+	if err := table.validateUKAccount(string(ab.BankId), ab.AccountNumber); err != nil {
+		errors = append(errors, err)
+	}
+	return errors
+}
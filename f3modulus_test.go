@@ -0,0 +1,190 @@
+package form3
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUKModulusCheck(t *testing.T) {
+	accounts := []struct {
+		scenario      string
+		sortCode      string
+		accountNumber string
+		expectError   bool
+	}{
+		{"Pass modulus 10 check", "089999", "66374958", false},
+		{"Pass modulus 11 check", "107999", "88837491", false},
+		{"Pass double alternate check", "202959", "63748472", false},
+		{"Fail modulus 10 check", "089999", "66374959", true},
+		{"Fail modulus 11 check", "107999", "88837493", true},
+		{"Fail double alternate check", "202959", "63748473", true},
+		{"Non standard account numbers are left to the length validator", "107999", "8883749", false},
+		{"Sort codes missing from the table cannot be checked", "400300", "41426819", false},
+	}
+
+	for _, a := range accounts {
+		t.Run(a.scenario, func(t *testing.T) {
+			err := bundledModulusTable.validateUKAccount(a.sortCode, a.accountNumber)
+			switch a.expectError {
+			case true:
+				var invalidAccount *InvalidUKAccount
+				if !errors.As(err, &invalidAccount) {
+					t.Errorf("expected an *InvalidUKAccount for %s %s, got %v", a.sortCode, a.accountNumber, err)
+				}
+			case false:
+				if err != nil {
+					t.Errorf("modulus check failed with %q on valid account %s %s", err, a.sortCode, a.accountNumber)
+				}
+			}
+		})
+	}
+}
+
+func TestUKModulusExceptions(t *testing.T) {
+	accounts := []struct {
+		scenario      string
+		weights       string
+		sortCode      string
+		accountNumber string
+		expectError   bool
+	}{
+		{"Exception 1 adds 27 to the total", "202959 202959 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 2 1 1",
+			"202959", "63748475", false},
+		{"Exception 1 fails without the 27", "202959 202959 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 2 1 1",
+			"202959", "63748472", true},
+		{"Exception 2 weights when a is not 0", "309070 309070 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 2\n309070 309070 MOD11 1 1 1 1 1 1 0 0 0 0 0 0 0 1 9",
+			"309070", "10000005", false},
+		{"Exception 2 weights when a is not 0 and g is 9", "309070 309070 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 2\n309070 309070 MOD11 1 1 1 1 1 1 0 0 0 0 0 0 0 1 9",
+			"309070", "10000096", false},
+		{"Exception 9 substitutes sort code 309634", "309070 309070 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 2\n309070 309070 MOD11 1 1 1 1 1 1 0 0 0 0 0 0 0 1 9",
+			"309070", "00000008", false},
+		{"Exceptions 2 & 9 fail when both checks fail", "309070 309070 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 2\n309070 309070 MOD11 1 1 1 1 1 1 0 0 0 0 0 0 0 1 9",
+			"309070", "00000003", true},
+		{"Exception 3 skips the second check when c is 6",
+			"089999 089999 MOD10 0 0 0 0 0 0 7 1 3 7 1 3 7 1\n089999 089999 DBLAL 0 0 0 0 0 0 0 0 0 0 0 0 0 1 3",
+			"089999", "66600004", false},
+		{"Exception 3 runs the second check otherwise",
+			"089999 089999 MOD10 0 0 0 0 0 0 7 1 3 7 1 3 7 1\n089999 089999 DBLAL 0 0 0 0 0 0 0 0 0 0 0 0 0 1 3",
+			"089999", "66300003", true},
+		{"Exception 4 remainder equals gh", "100000 100000 MOD11 0 0 0 0 0 0 1 1 1 1 1 1 0 0 4",
+			"100000", "50000005", false},
+		{"Exception 4 remainder differs from gh", "100000 100000 MOD11 0 0 0 0 0 0 1 1 1 1 1 1 0 0 4",
+			"100000", "50000006", true},
+		{"Exception 5 passes both checks", "938000 938696 MOD11 7 6 5 4 3 2 7 6 5 4 3 2 0 0 5\n938000 938696 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 0 0 5",
+			"938063", "55065200", false},
+		{"Exception 5 fails the double alternate check", "938000 938696 MOD11 7 6 5 4 3 2 7 6 5 4 3 2 0 0 5\n938000 938696 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 0 0 5",
+			"938063", "15764273", true},
+		{"Exception 5 fails the modulus 11 check", "938000 938696 MOD11 7 6 5 4 3 2 7 6 5 4 3 2 0 0 5\n938000 938696 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 0 0 5",
+			"938063", "15764264", true},
+		{"Exception 5 rejects a remainder of 1", "938000 938696 MOD11 7 6 5 4 3 2 7 6 5 4 3 2 0 0 5\n938000 938696 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 0 0 5",
+			"938063", "15763217", true},
+		{"Exception 6 foreign currency account", "100000 100000 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 6",
+			"100000", "40000011", false},
+		{"Exception 6 sterling account", "100000 100000 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 6",
+			"100000", "30000011", true},
+		{"Exception 7 zeroises u-b when g is 9", "100000 100000 MOD11 1 1 1 1 1 1 8 7 6 5 4 3 2 1 7",
+			"100000", "12345695", false},
+		{"Without exception 7 u-b are weighted", "100000 100000 MOD11 1 1 1 1 1 1 8 7 6 5 4 3 2 1",
+			"100000", "12345695", true},
+		{"Exception 8 substitutes sort code 090126", "100000 100000 MOD11 1 1 1 1 1 1 0 0 0 0 0 0 0 1 8",
+			"100000", "00000004", false},
+		{"Exception 10 zeroises u-b when ab is 09 and g is 9", "871427 871427 MOD11 1 1 1 1 1 1 1 1 0 0 0 0 1 1 10\n871427 871427 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 11",
+			"871427", "09000092", false},
+		{"Exception 10 zeroises u-b when ab is 99 and g is 9", "871427 871427 MOD11 1 1 1 1 1 1 1 1 0 0 0 0 1 1 10\n871427 871427 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 11",
+			"871427", "99000092", false},
+		{"Exception 11 passes when exception 10 fails", "871427 871427 MOD11 1 1 1 1 1 1 1 1 0 0 0 0 1 1 10\n871427 871427 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 11",
+			"871427", "19000090", false},
+		{"Exceptions 10 & 11 fail when both checks fail", "871427 871427 MOD11 1 1 1 1 1 1 1 1 0 0 0 0 1 1 10\n871427 871427 MOD11 0 0 0 0 0 0 0 0 0 0 0 0 0 1 11",
+			"871427", "19000092", true},
+		{"Exceptions 12 & 13 pass when either check passes",
+			"089999 089999 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 12\n089999 089999 MOD10 0 0 0 0 0 0 7 1 3 7 1 3 7 1 13",
+			"089999", "66374958", false},
+		{"Both checks must pass without exceptions",
+			"089999 089999 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1\n089999 089999 MOD10 0 0 0 0 0 0 7 1 3 7 1 3 7 1",
+			"089999", "66374958", true},
+		{"Exception 14 drops a trailing 0, 1 or 9", "180002 180002 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 14",
+			"180002", "00000190", false},
+		{"Exception 14 rejects other trailing digits", "180002 180002 MOD11 0 0 0 0 0 0 8 7 6 5 4 3 2 1 14",
+			"180002", "00000195", true},
+	}
+
+	for _, a := range accounts {
+		t.Run(a.scenario, func(t *testing.T) {
+			table, err := parseModulusTable(strings.NewReader(a.weights), nil)
+			if err != nil {
+				t.Fatalf("invalid weights %q: %v", a.weights, err)
+			}
+
+			err = table.validate(a.sortCode, a.accountNumber)
+			if a.expectError && err == nil {
+				t.Errorf("modulus check did not fail when it was expected too! %s %s", a.sortCode, a.accountNumber)
+			}
+			if !a.expectError && err != nil {
+				t.Errorf("modulus check failed with %q on valid account %s %s", err, a.sortCode, a.accountNumber)
+			}
+		})
+	}
+}
+
+func TestUKModulusSortCodeSubstitution(t *testing.T) {
+	weights := "938000 938696 MOD11 7 6 5 4 3 2 7 6 5 4 3 2 0 0 5\n938000 938696 DBLAL 2 1 2 1 2 1 2 1 2 1 2 1 0 0 5"
+
+	table, err := parseModulusTable(strings.NewReader(weights), strings.NewReader("938200 938063\n"))
+	if err != nil {
+		t.Fatalf("invalid modulus table: %v", err)
+	}
+	if err := table.validate("938200", "55065200"); err != nil {
+		t.Errorf("expected exception 5 to check 938200 as 938063, got %v", err)
+	}
+
+	table, _ = parseModulusTable(strings.NewReader(weights), nil)
+	if err := table.validate("938200", "55065200"); err == nil {
+		t.Errorf("expected 938200 to fail without its substitution")
+	}
+}
+
+func TestWithModulusTable(t *testing.T) {
+	if _, err := New(WithBaseURL("http://localhost"),
+		WithModulusTable(strings.NewReader("089999 089999 MOD12 0 0 0 0 0 0 7 1 3 7 1 3 7 1"), nil)); err == nil {
+		t.Errorf("expected an unknown modulus method to be rejected")
+	}
+
+	client, err := New(WithBaseURL("http://localhost"),
+		WithModulusTable(strings.NewReader("100000 100000 MOD10 0 0 0 0 0 0 0 0 0 0 0 0 0 1"),
+			strings.NewReader("938173 938017\n")))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	validate := func(c *F3Client, sortCode string, accountNumber string) []error {
+		return ukModulusValidator(createBuilder{client: c, AccountAttributes: AccountAttributes{
+			BankId: BankId(sortCode), AccountNumber: accountNumber,
+		}})
+	}
+
+	if errs := validate(client, "100000", "00000001"); len(errs) == 0 {
+		t.Errorf("expected the loaded table to be used")
+	}
+	if errs := validate(client, "089999", "66374959"); len(errs) != 0 {
+		t.Errorf("expected the bundled table to be replaced, got %v", errs)
+	}
+	if errs := validate(SetupF3Client(F3Env{}), "089999", "66374959"); len(errs) == 0 {
+		t.Errorf("expected other clients to keep the bundled table")
+	}
+}
+
+func TestCreateBuilderRunsUKModulusCheck(t *testing.T) {
+	errs := postValidators(createBuilder{AccountAttributes: AccountAttributes{
+		Country: "GB", BankId: "107999", Bic: "NWBKGB22", BankIdCode: "GBDSC", AccountNumber: "88837493",
+		AccountClassification: "Personal",
+	}})
+
+	for _, err := range errs {
+		var invalidAccount *InvalidUKAccount
+		if errors.As(err, &invalidAccount) && validationRule(err) == "ukModulus" {
+			return
+		}
+	}
+	t.Errorf("expected a ukModulus validation error, got %v", errs)
+}
@@ -0,0 +1,341 @@
+package form3
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 60 * time.Second
+
+// Clock provides the current time to the client, allowing tests to control the Date header and rate limit
+// reset calculations.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Option configures a client created with New. Options are applied in order, so an option given after
+// FromEnv overrides the value read from the environment.
+type Option func(o *clientOptions) error
+
+type clientOptions struct {
+	env            F3Env
+	httpClient     *http.Client
+	transport      http.RoundTripper
+	tlsConfig      *tls.Config
+	logger         StructuredLogger
+	userAgent      string
+	clock          Clock
+	authenticator  Authenticator
+	signer         *RequestSigner
+	rateLimiter    *RateLimiter
+	circuitBreaker *CircuitBreaker
+	middlewares    []Middleware
+	metrics        Metrics
+	tracer         Tracer
+
+	modulusTable *modulusTable
+}
+
+// New creates a client configured only by the given options, so that several clients with different
+// settings can be used side by side in one process.
+func New(opts ...Option) (*F3Client, error) {
+	o := &clientOptions{
+		env: F3Env{
+			F3MaxRetries:   3,
+			F3RetryWaitMin: defaultRetryWaitMin,
+			F3RetryWaitMax: defaultRetryWaitMax,
+			F3RateBurst:    1,
+		},
+		clock: systemClock{},
+	}
+
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.env.F3BaseURL == "" {
+		return nil, fmt.Errorf("base url not set. use WithBaseURL or FromEnv with the 'F3BaseURL' environmental variable")
+	}
+
+	httpClient := &http.Client{}
+	if o.httpClient != nil {
+		copied := *o.httpClient
+		httpClient = &copied
+	} else if o.env.F3Timeout <= 0 {
+		o.env.F3Timeout = defaultTimeout
+	}
+
+	if o.env.F3Timeout > 0 {
+		httpClient.Timeout = o.env.F3Timeout
+	}
+	o.env.F3Timeout = httpClient.Timeout
+
+	if o.transport != nil {
+		httpClient.Transport = o.transport
+	}
+
+	if o.tlsConfig != nil {
+		transport, err := tlsTransport(httpClient.Transport, o.tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = transport
+	}
+
+	client := &F3Client{
+		Env:            o.env,
+		HTTPClient:     httpClient,
+		RateLimiter:    o.rateLimiter,
+		CircuitBreaker: o.circuitBreaker,
+		Authenticator:  o.authenticator,
+		Signer:         o.signer,
+		logger:         o.logger,
+		userAgent:      o.userAgent,
+		clock:          o.clock,
+		middlewares:    o.middlewares,
+		Metrics:        o.metrics,
+		Tracer:         o.tracer,
+
+		modulusTable: o.modulusTable,
+	}
+
+	if client.RateLimiter == nil && o.env.F3RateLimit > 0 {
+		client.RateLimiter = NewRateLimiter(o.env.F3RateLimit, o.env.F3RateBurst)
+	}
+
+	if credentials, ok := client.Authenticator.(*ClientCredentials); ok {
+		if credentials.HTTPClient == nil {
+			credentials.HTTPClient = client.HTTPClient
+		}
+		if credentials.Logger == nil {
+			credentials.Logger = client.logger
+		}
+	}
+
+	return client, nil
+}
+
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) error {
+		o.env.F3BaseURL = baseURL
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each http request, overriding the timeout of a client given to
+// WithHTTPClient.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) error {
+		o.env.F3Timeout = timeout
+		return nil
+	}
+}
+
+func WithMaxRetries(retries int) Option {
+	return func(o *clientOptions) error {
+		o.env.F3MaxRetries = retries
+		return nil
+	}
+}
+
+func WithRetryWait(min, max time.Duration) Option {
+	return func(o *clientOptions) error {
+		o.env.F3RetryWaitMin = min
+		o.env.F3RetryWaitMax = max
+		return nil
+	}
+}
+
+// WithHTTPClient uses a copy of the given client to send requests, leaving the caller's client unchanged.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) error {
+		o.httpClient = httpClient
+		return nil
+	}
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) error {
+		o.transport = transport
+		return nil
+	}
+}
+
+func WithTLS(config *tls.Config) Option {
+	return func(o *clientOptions) error {
+		o.tlsConfig = config
+		return nil
+	}
+}
+
+func WithLogger(logger StructuredLogger) Option {
+	return func(o *clientOptions) error {
+		o.logger = logger
+		return nil
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) error {
+		o.userAgent = userAgent
+		return nil
+	}
+}
+
+func WithClock(clock Clock) Option {
+	return func(o *clientOptions) error {
+		o.clock = clock
+		return nil
+	}
+}
+
+func WithAuth(authenticator Authenticator) Option {
+	return func(o *clientOptions) error {
+		o.authenticator = authenticator
+		return nil
+	}
+}
+
+func WithSigner(signer *RequestSigner) Option {
+	return func(o *clientOptions) error {
+		o.signer = signer
+		return nil
+	}
+}
+
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *clientOptions) error {
+		o.rateLimiter = limiter
+		return nil
+	}
+}
+
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(o *clientOptions) error {
+		o.circuitBreaker = breaker
+		return nil
+	}
+}
+
+// FromEnv reads the client configuration from the F3* environmental variables when the option is applied.
+// Variables which are not set leave the current value in place.
+func FromEnv() Option {
+	return fromEnv(true)
+}
+
+// fromEnv reads the F3* environmental variables. A lenient read logs malformed timeouts, retries and rate limits
+// and keeps their defaults, as NewF3Client always has, while tls and signing settings must always be valid.
+func fromEnv(strict bool) Option {
+	return func(o *clientOptions) error {
+		check := func(err error) error {
+			if err != nil && !strict {
+				Logger.Log(LevelWarn, "ignoring environmental variable, using the default", F("error", err))
+				return nil
+			}
+			return err
+		}
+
+		if baseURL := os.Getenv(F3BaseURL); baseURL != "" {
+			o.env.F3BaseURL = baseURL
+		}
+
+		if err := check(durationFromEnv(F3Timeout, &o.env.F3Timeout)); err != nil {
+			return err
+		}
+
+		if err := check(intFromEnv(F3MaxRetries, &o.env.F3MaxRetries)); err != nil {
+			return err
+		}
+
+		if err := check(durationFromEnv(F3RetryWaitMin, &o.env.F3RetryWaitMin)); err != nil {
+			return err
+		}
+
+		if err := check(durationFromEnv(F3RetryWaitMax, &o.env.F3RetryWaitMax)); err != nil {
+			return err
+		}
+
+		if value := os.Getenv(F3RateLimit); value != "" {
+			limit, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				err = fmt.Errorf("invalid %q environmental variable %q. error: %w", F3RateLimit, value, err)
+			} else {
+				o.env.F3RateLimit = limit
+			}
+			if err := check(err); err != nil {
+				return err
+			}
+		}
+
+		if err := check(intFromEnv(F3RateBurst, &o.env.F3RateBurst)); err != nil {
+			return err
+		}
+
+		if settings, ok, err := tlsSettingsFromEnv(); err != nil {
+			return err
+		} else if ok {
+			if o.tlsConfig, err = NewTLSConfig(settings); err != nil {
+				return err
+			}
+		}
+
+		if tokenURL := os.Getenv(F3TokenURL); tokenURL != "" {
+			o.authenticator = NewClientCredentials(tokenURL, os.Getenv(F3ClientID), os.Getenv(F3ClientSecret),
+				strings.Fields(os.Getenv(F3Scopes))...)
+		}
+
+		if keyPath := os.Getenv(F3SigningKey); keyPath != "" {
+			key, err := LoadSigningKey(keyPath)
+			if err != nil {
+				return err
+			}
+
+			if o.signer, err = NewRequestSigner(os.Getenv(F3SigningKeyId), key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func durationFromEnv(name string, target *time.Duration) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %q environmental variable %q. error: %w", name, value, err)
+	}
+	*target = duration
+	return nil
+}
+
+func intFromEnv(name string, target *int) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %q environmental variable %q. error: %w", name, value, err)
+	}
+	*target = i
+	return nil
+}
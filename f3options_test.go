@@ -0,0 +1,134 @@
+package form3
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestNewClientsSideBySide(t *testing.T) {
+	var agents []string
+	var dates []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		dates = append(dates, r.Header.Get("Date"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clock := fixedClock(time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC))
+	sandbox, err := New(WithBaseURL(server.URL), WithTimeout(5*time.Second), WithUserAgent("sandbox"), WithClock(clock))
+	if err != nil {
+		t.Fatalf("failed to create sandbox client %q", err)
+	}
+
+	production, err := New(WithBaseURL(server.URL), WithTimeout(30*time.Second), WithUserAgent("production"))
+	if err != nil {
+		t.Fatalf("failed to create production client %q", err)
+	}
+
+	if sandbox.HTTPClient.Timeout != 5*time.Second || production.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("expected timeouts of 5s and 30s but got %s and %s", sandbox.HTTPClient.Timeout,
+			production.HTTPClient.Timeout)
+	}
+
+	for _, client := range []*F3Client{sandbox, production} {
+		if err := client.Delete().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background()); err != nil {
+			t.Fatalf("delete request failed with %q", err)
+		}
+	}
+
+	if agents[0] != "sandbox" || agents[1] != "production" {
+		t.Errorf("expected user agents [sandbox production] but got %v", agents)
+	}
+
+	if dates[0] != "Mon, 01 Mar 2021 10:00:00 GMT" {
+		t.Errorf("expected Date header from the clock but got %q", dates[0])
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	logger := NewStdLogger(log.New(os.Stderr, "TEST: ", 0), LevelDebug)
+	shared := &http.Client{Timeout: 2 * time.Second}
+	transport := &http.Transport{}
+
+	client, err := New(WithBaseURL("localhost:8080"), WithHTTPClient(shared), WithTransport(transport),
+		WithMaxRetries(7), WithRetryWait(time.Millisecond, time.Second), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("failed to create client %q", err)
+	}
+
+	if client.HTTPClient == shared || shared.Transport != nil {
+		t.Errorf("expected the given http client to be copied rather than modified")
+	}
+
+	if client.HTTPClient.Timeout != 2*time.Second || client.HTTPClient.Transport != transport {
+		t.Errorf("expected the timeout and transport to be taken from the options")
+	}
+
+	if client.Env.F3MaxRetries != 7 || client.Env.F3RetryWaitMin != time.Millisecond || client.Env.F3RetryWaitMax != time.Second {
+		t.Errorf("unexpected retry settings %+v", client.Env)
+	}
+
+	if redacting, ok := client.log().(redactingLogger); !ok || redacting.next != logger {
+		t.Errorf("expected the client to log with the given logger")
+	}
+
+	if _, err := New(WithTimeout(time.Second)); err == nil {
+		t.Errorf("expected an error when no base url is configured")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	env := map[string]string{F3BaseURL: "localhost:8080", F3Timeout: "10s", F3MaxRetries: "5", F3RateLimit: "20"}
+	for key, value := range env {
+		previous, ok := os.LookupEnv(key)
+		_ = os.Setenv(key, value)
+		defer func(key, previous string, ok bool) {
+			if ok {
+				_ = os.Setenv(key, previous)
+			} else {
+				_ = os.Unsetenv(key)
+			}
+		}(key, previous, ok)
+	}
+
+	client, err := New(WithMaxRetries(1), FromEnv(), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("failed to create client from env %q", err)
+	}
+
+	if client.Env.F3BaseURL != "localhost:8080" || client.Env.F3MaxRetries != 5 || client.RateLimiter == nil {
+		t.Errorf("expected settings from the environment but got %+v", client.Env)
+	}
+
+	if client.HTTPClient.Timeout != time.Second {
+		t.Errorf("expected an option after FromEnv to override the environment but got %s", client.HTTPClient.Timeout)
+	}
+
+	_ = os.Setenv(F3Timeout, "ten seconds")
+	if _, err := New(FromEnv()); err == nil || !strings.Contains(err.Error(), F3Timeout) {
+		t.Errorf("expected an error naming the invalid variable but got %v", err)
+	}
+
+	_ = os.Setenv(F3MaxRetries, "five")
+	client, err = NewF3Client()
+	if err != nil {
+		t.Fatalf("expected NewF3Client to fall back to the defaults but got %q", err)
+	}
+	if client.HTTPClient.Timeout != defaultTimeout || client.Env.F3MaxRetries != 3 {
+		t.Errorf("expected the default timeout and retries but got %s and %d", client.HTTPClient.Timeout,
+			client.Env.F3MaxRetries)
+	}
+}
@@ -0,0 +1,179 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// epochThreshold separates X-RateLimit-Reset values sent as unix timestamps from those sent as a number of
+// seconds until the window resets.
+const epochThreshold = 1000000000
+
+func newF3RateLimitError(header http.Header, now time.Time) *F3RateLimitError {
+	err := &F3RateLimitError{
+		RetryAfter: parseRetryAfter(header.Get(headerRetryAfter), now),
+		Limit:      parseHeaderInt(header.Get(headerRateLimitLimit), -1),
+		Remaining:  parseHeaderInt(header.Get(headerRateLimitRemaining), -1),
+	}
+
+	if reset := parseHeaderInt(header.Get(headerRateLimitReset), -1); reset >= epochThreshold {
+		err.Reset = time.Unix(int64(reset), 0)
+	} else if reset >= 0 {
+		err.Reset = now.Add(time.Duration(reset) * time.Second)
+	}
+
+	return err
+}
+
+// parseRetryAfter supports both the delay-seconds and HTTP-date forms of the Retry-After header.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+func parseHeaderInt(value string, fallback int) int {
+	if i, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return i
+	}
+	return fallback
+}
+
+// RateLimiter is a token bucket allowing requestsPerSecond on average with bursts of up to burst requests.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or the context is done. A token reserved by a cancelled wait is
+// handed back to the bucket.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	wait := l.reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		l.cancel()
+		return fmt.Errorf("rate limiter wait of %s exceeds context deadline. %w", wait, context.DeadlineExceeded)
+	}
+
+	if err := sleep(ctx, wait); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
+
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+type organisationKey struct{}
+
+// WithOrganisation tags the context with the organisation a request is made on behalf of, so that requests
+// are throttled by the rate limiter registered for that organisation. Creates are tagged with the organisation of
+// their body, fetch, list, update and delete requests must be tagged by the caller.
+func WithOrganisation(ctx context.Context, organisationId UUID) context.Context {
+	return context.WithValue(ctx, organisationKey{}, organisationId)
+}
+
+func organisationFromContext(ctx context.Context) (UUID, bool) {
+	organisationId, ok := ctx.Value(organisationKey{}).(UUID)
+	return organisationId, ok && !organisationId.IsZeroValue()
+}
+
+func (c *F3Client) SetOrganisationRateLimiter(organisationId UUID, limiter *RateLimiter) {
+	if limiter == nil {
+		c.organisationLimiters.Delete(organisationId)
+		return
+	}
+	c.organisationLimiters.Store(organisationId, limiter)
+}
+
+func (c *F3Client) throttle(ctx context.Context) error {
+	if organisationId, ok := organisationFromContext(ctx); ok {
+		if limiter, ok := c.organisationLimiters.Load(organisationId); ok {
+			if err := limiter.(*RateLimiter).Wait(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.RateLimiter != nil {
+		return c.RateLimiter.Wait(ctx)
+	}
+	return nil
+}
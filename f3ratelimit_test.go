@@ -0,0 +1,210 @@
+package form3
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, time.October, 21, 7, 28, 0, 0, time.UTC)
+
+	values := []struct {
+		scenario string
+		value    string
+		expected time.Duration
+	}{
+		{"Delay Seconds", "120", 2 * time.Minute},
+		{"Zero Seconds", "0", 0},
+		{"Negative Seconds", "-5", 0},
+		{"HTTP Date", "Wed, 21 Oct 2020 07:28:30 GMT", 30 * time.Second},
+		{"HTTP Date In The Past", "Wed, 21 Oct 2020 07:27:00 GMT", 0},
+		{"Corrupted Value", "soon", 0},
+		{"Empty Value", "", 0},
+	}
+
+	for _, v := range values {
+		t.Run(v.scenario, func(t *testing.T) {
+			if wait := parseRetryAfter(v.value, now); wait != v.expected {
+				t.Errorf("retry after %q parsed as %s expected %s", v.value, wait, v.expected)
+			}
+		})
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	now := time.Date(2020, time.October, 21, 7, 28, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Set(headerRetryAfter, "3")
+	header.Set(headerRateLimitLimit, "1000")
+	header.Set(headerRateLimitRemaining, "0")
+	header.Set(headerRateLimitReset, "60")
+
+	err := newF3RateLimitError(header, now)
+	if err.RetryAfter != 3*time.Second || err.Limit != 1000 || err.Remaining != 0 || !err.Reset.Equal(now.Add(time.Minute)) {
+		t.Errorf("rate limit headers parsed incorrectly %+v", err)
+	}
+
+	header.Set(headerRateLimitReset, "1603265340")
+	if err := newF3RateLimitError(header, now); !err.Reset.Equal(time.Unix(1603265340, 0)) {
+		t.Errorf("epoch reset header parsed incorrectly %s", err.Reset)
+	}
+
+	if !errors.Is(err, F3StatusTooManyRequests) {
+		t.Errorf("rate limit error should match the 'F3StatusTooManyRequests' sentinel")
+	}
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set(headerRetryAfter, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := testClient(server, 1)
+	client.Env.F3RetryWaitMax = 2 * time.Second
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	start := time.Now()
+	if err := client.request(req, nil); err != nil {
+		t.Errorf("request failed with %q after retrying a 429", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("client retried after %s without waiting for the Retry-After header", elapsed)
+	}
+}
+
+func TestRetryWaitIsExact(t *testing.T) {
+	now := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+	client := SetupF3Client(F3Env{F3RetryWaitMin: time.Millisecond, F3RetryWaitMax: time.Second})
+	client.clock = fixedClock(now)
+
+	waits := []struct {
+		scenario string
+		err      *F3RateLimitError
+		expected time.Duration
+		retry    bool
+	}{
+		{"Retry-After", &F3RateLimitError{RetryAfter: 500 * time.Millisecond}, 500 * time.Millisecond, true},
+		{"Retry-After above the maximum wait", &F3RateLimitError{RetryAfter: 24 * time.Hour}, 24 * time.Hour, false},
+		{"Reset measured from the client clock", &F3RateLimitError{Reset: now.Add(800 * time.Millisecond)},
+			800 * time.Millisecond, true},
+		{"Reset above the maximum wait", &F3RateLimitError{Reset: now.Add(time.Hour)}, time.Hour, false},
+	}
+
+	for _, w := range waits {
+		t.Run(w.scenario, func(t *testing.T) {
+			wait, retry := client.retryWait(context.Background(), 1, w.err)
+			if wait != w.expected || retry != w.retry {
+				t.Errorf("expected a wait of %s (retry %t) but got %s (retry %t)", w.expected, w.retry, wait, retry)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, retry := client.retryWait(ctx, 1, &F3RateLimitError{RetryAfter: 500 * time.Millisecond}); retry {
+		t.Errorf("expected a wait beyond the context deadline not to be retried")
+	}
+}
+
+func TestRetryGivesUpOnLongRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set(headerRetryAfter, "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := testClient(server, 3)
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	start := time.Now()
+	err := client.request(req, nil)
+
+	var rateLimitErr *F3RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != time.Hour {
+		t.Errorf("expected the *F3RateLimitError to be returned, got %v", err)
+	}
+	if calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("expected the request to give up without retrying, made %d calls", calls)
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(10, 3)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Errorf("burst request %d failed with %q", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("burst of 3 requests should not be throttled, took %s", elapsed)
+	}
+
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("throttled request failed with %q", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("request exceeding the burst should wait for a token, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterContextCancellation(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	_ = limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline error while waiting for a token but got %v", err)
+	}
+}
+
+func TestOrganisationRateLimiter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := testClient(server, 0)
+	client.SetOrganisationRateLimiter("ea68b98a-471a-4c71-ac83-0f96a2bee973", NewRateLimiter(0.1, 1))
+
+	throttled := WithOrganisation(context.Background(), "ea68b98a-471a-4c71-ac83-0f96a2bee973")
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if err := client.request(req.WithContext(throttled), nil); err != nil {
+		t.Errorf("first request for organisation failed with %q", err)
+	}
+
+	ctx, cancel := context.WithTimeout(throttled, 10*time.Millisecond)
+	defer cancel()
+	if err := client.request(req.WithContext(ctx), nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the organisation's rate limiter to reject the request but got %v", err)
+	}
+
+	other := WithOrganisation(context.Background(), "81d62ace-23f2-4aff-a7d6-60d7674bc5bb")
+	if err := client.request(req.WithContext(other), nil); err != nil {
+		t.Errorf("other organisations should not be throttled, failed with %q", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 requests to reach the server but received %d", calls)
+	}
+}
@@ -0,0 +1,163 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultRetryWaitMin = 100 * time.Millisecond
+	defaultRetryWaitMax = 10 * time.Second
+)
+
+var retryableErrors = []error{
+	F3StatusTooManyRequests,
+	F3StatusInternalServerError,
+	F3StatusBadGateway,
+	F3StatusServiceUnavailable,
+	F3StatusGatewayTimeout,
+	ErrInjectedFault,
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *F3APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	for _, retryable := range retryableErrors {
+		if errors.Is(err, retryable) {
+			return true
+		}
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && isTransientNetworkError(urlErr)
+}
+
+// isTransientNetworkError reports whether a transport error may succeed on retry, such as a timeout or a dropped
+// connection. Permanent failures such as tls verification errors or malformed urls are not retried.
+func isTransientNetworkError(err *url.Error) bool {
+	if err.Timeout() || err.Temporary() {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// retryWait honours the wait requested by the api on a 429 and otherwise falls back to backoff. The requested wait
+// is returned as is, retrying any sooner would only be rejected again, so false is returned when it is beyond
+// F3RetryWaitMax or the deadline of the context and the request should give up instead.
+func (c *F3Client) retryWait(ctx context.Context, attempt int, err error) (time.Duration, bool) {
+	var rateLimitErr *F3RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		return c.backoff(attempt), true
+	}
+
+	wait := rateLimitErr.RetryAfter
+	if reset := rateLimitErr.Reset.Sub(c.now()); wait <= 0 && rateLimitErr.Remaining == 0 && reset > 0 {
+		wait = reset
+	}
+	if wait <= 0 {
+		return c.backoff(attempt), true
+	}
+
+	if wait > c.retryWaitMax() {
+		return wait, false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return wait, false
+	}
+	return wait, true
+}
+
+// retryWaitMax returns F3RetryWaitMax, defaulting an unset value to defaultRetryWaitMax and raising one below the
+// minimum wait to it.
+func (c *F3Client) retryWaitMax() time.Duration {
+	min, max := c.Env.F3RetryWaitMin, c.Env.F3RetryWaitMax
+	if min <= 0 {
+		min = defaultRetryWaitMin
+	}
+	if max <= 0 {
+		max = defaultRetryWaitMax
+	}
+	if max < min {
+		max = min
+	}
+	return max
+}
+
+// backoff returns the exponential delay for the given retry attempt (starting at 1), with half of the
+// delay randomised so that concurrent clients retrying the same failure do not hit the api in lockstep.
+func (c *F3Client) backoff(attempt int) time.Duration {
+	min, max := c.Env.F3RetryWaitMin, c.retryWaitMax()
+	if min <= 0 {
+		min = defaultRetryWaitMin
+	}
+
+	wait := min
+	for i := 1; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func rewindableBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
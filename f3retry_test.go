@@ -0,0 +1,233 @@
+package form3
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func testClient(server *httptest.Server, maxRetries int) *F3Client {
+	return SetupF3Client(F3Env{
+		F3BaseURL:      strings.TrimPrefix(server.URL, "http://"),
+		F3MaxRetries:   maxRetries,
+		F3RetryWaitMin: time.Millisecond,
+		F3RetryWaitMax: 5 * time.Millisecond,
+	})
+}
+
+func TestRetryOnRetryableStatus(t *testing.T) {
+	statuses := []struct {
+		scenario string
+		status   int
+		expected error
+	}{
+		{"Too Many Requests", http.StatusTooManyRequests, F3StatusTooManyRequests},
+		{"Internal Server Error", http.StatusInternalServerError, F3StatusInternalServerError},
+		{"Bad Gateway", http.StatusBadGateway, F3StatusBadGateway},
+		{"Service Unavailable", http.StatusServiceUnavailable, F3StatusServiceUnavailable},
+		{"Gateway Timeout", http.StatusGatewayTimeout, F3StatusGatewayTimeout},
+	}
+
+	for _, s := range statuses {
+		t.Run(s.scenario, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) < 3 {
+					w.WriteHeader(s.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			if err := testClient(server, 3).request(req, nil); err != nil {
+				t.Errorf("request failed with %q after retrying status %d", err, s.status)
+			}
+
+			if calls != 3 {
+				t.Errorf("expected 3 attempts but the server received %d", calls)
+			}
+		})
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	err := testClient(server, 2).request(req, nil)
+	if !errors.Is(err, F3StatusBadGateway) {
+		t.Errorf("expected bad gateway error once retries were exhausted but got %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 attempts but the server received %d", calls)
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest("DELETE", server.URL, nil)
+	if err := testClient(server, 3).request(req, nil); !errors.Is(err, F3StatusConflict) {
+		t.Errorf("expected conflict error but got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("non retryable errors should not be retried, the server received %d attempts", calls)
+	}
+}
+
+func TestRetryResendsPostBody(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(body), "81d62ace-23f2-4aff-a7d6-60d7674bc5bb") {
+			t.Errorf("attempt %d received a corrupted body %q", atomic.LoadInt32(&calls)+1, body)
+		}
+
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	response := make(chan *Payload, 1)
+	errors := make(chan []error, 1)
+
+	testClient(server, 3).Create().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+		WithCountry(Countries["GB"]).
+		WithBankId("000006").
+		WithBic("NWBKGB22").
+		WithBankIdCode("GBDSC").
+		WithAccountClassification("Personal").
+		Request(context.Background(), response, errors)
+
+	if err := <-errors; err != nil {
+		t.Errorf("create request failed with %v", err)
+	}
+
+	if payload := <-response; payload == nil || payload.Data.Id != "81d62ace-23f2-4aff-a7d6-60d7674bc5bb" {
+		t.Errorf("unexpected payload returned %+v", payload)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 attempts but the server received %d", calls)
+	}
+}
+
+func TestRetryStopsOnContextCancellation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := testClient(server, 10)
+	client.Env.F3RetryWaitMin = time.Minute
+	client.Env.F3RetryWaitMax = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	start := time.Now()
+	err := client.request(req.WithContext(ctx), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline error but got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request did not stop waiting on context cancellation, took %s", elapsed)
+	}
+}
+
+func TestRetryOnlyTransientNetworkErrors(t *testing.T) {
+	errs := []struct {
+		scenario  string
+		err       error
+		retryable bool
+	}{
+		{"Connection refused", &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, true},
+		{"Connection reset", &url.Error{Op: "Get", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}, true},
+		{"Unexpected EOF", &url.Error{Op: "Get", Err: io.EOF}, true},
+		{"Timeout", &url.Error{Op: "Get", Err: &net.DNSError{IsTimeout: true}}, true},
+		{"Unknown certificate authority", &url.Error{Op: "Get", Err: x509.UnknownAuthorityError{}}, false},
+		{"Unsupported protocol scheme", &url.Error{Op: "Get", Err: errors.New("unsupported protocol scheme \"ftp\"")}, false},
+	}
+
+	for _, e := range errs {
+		t.Run(e.scenario, func(t *testing.T) {
+			if retryable := isRetryable(context.Background(), e.err); retryable != e.retryable {
+				t.Errorf("expected retryable %t for %v", e.retryable, e.err)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	client := SetupF3Client(F3Env{
+		F3RetryWaitMin: 100 * time.Millisecond,
+		F3RetryWaitMax: time.Second,
+	})
+
+	attempts := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{4, 400 * time.Millisecond, 800 * time.Millisecond},
+		{5, 500 * time.Millisecond, time.Second},
+		{30, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, a := range attempts {
+		for i := 0; i < 50; i++ {
+			if wait := client.backoff(a.attempt); wait < a.min || wait > a.max {
+				t.Errorf("attempt %d backoff %s outside of [%s, %s]", a.attempt, wait, a.min, a.max)
+			}
+		}
+	}
+}
+
+func TestBackoffDefaultsUnsetWaits(t *testing.T) {
+	client := SetupF3Client(F3Env{})
+
+	for i := 0; i < 50; i++ {
+		if wait := client.backoff(4); wait < 400*time.Millisecond || wait > 800*time.Millisecond {
+			t.Errorf("attempt 4 backoff %s outside of [400ms, 800ms]", wait)
+		}
+	}
+	if max := client.retryWaitMax(); max != defaultRetryWaitMax {
+		t.Errorf("expected an unset F3RetryWaitMax to default to %s, got %s", defaultRetryWaitMax, max)
+	}
+}
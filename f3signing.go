@@ -0,0 +1,274 @@
+package form3
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	headerSignature = "Signature"
+	headerDigest    = "Digest"
+
+	algorithmRSASHA256 = "rsa-sha256"
+	algorithmEd25519   = "ed25519"
+
+	defaultSignatureSkew = 5 * time.Minute
+)
+
+var signedHeaders = []string{"(request-target)", "host", "date", "digest"}
+
+var ErrMissingSignature = errors.New("request is missing the Signature header")
+var ErrInvalidSignature = errors.New("request signature verification failed")
+var ErrInvalidDigest = errors.New("request body does not match the Digest header")
+var ErrUnknownKeyId = errors.New("request was signed with an unknown key id")
+
+// RequestSigner signs requests following the HTTP message signatures draft, adding a Digest header over the
+// body and a Signature header covering the request target, host, date and digest.
+type RequestSigner struct {
+	KeyId string
+	Key   crypto.Signer
+}
+
+func NewRequestSigner(keyId string, key crypto.Signer) (*RequestSigner, error) {
+	if _, err := signatureAlgorithm(key.Public()); err != nil {
+		return nil, err
+	}
+	return &RequestSigner{KeyId: keyId, Key: key}, nil
+}
+
+// LoadSigningKey reads an RSA or Ed25519 private key from a PEM encoded PKCS#8 or PKCS#1 file.
+func LoadSigningKey(path string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signing key %q. error: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in signing key %q", path)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing signing key %q. error: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	return signer, nil
+}
+
+func (s *RequestSigner) Sign(req *http.Request) error {
+	algorithm, err := signatureAlgorithm(s.Key.Public())
+	if err != nil {
+		return err
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return fmt.Errorf("error reading body for signing. error: %w", err)
+	}
+
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	req.Header.Set(headerDigest, digest(body))
+
+	var signature []byte
+	message := []byte(signingString(req, signedHeaders))
+	switch algorithm {
+	case algorithmRSASHA256:
+		hashed := sha256.Sum256(message)
+		signature, err = s.Key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	case algorithmEd25519:
+		signature, err = s.Key.Sign(rand.Reader, message, crypto.Hash(0))
+	}
+	if err != nil {
+		return fmt.Errorf("error signing request. error: %w", err)
+	}
+
+	req.Header.Set(headerSignature, fmt.Sprintf(`keyId=%q,algorithm=%q,headers=%q,signature=%q`,
+		s.KeyId, algorithm, strings.Join(signedHeaders, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+// SignatureVerifier checks requests signed by a RequestSigner against a set of public keys indexed by key id.
+type SignatureVerifier struct {
+	Keys    map[string]crypto.PublicKey
+	MaxSkew time.Duration
+}
+
+func NewSignatureVerifier(keys map[string]crypto.PublicKey) *SignatureVerifier {
+	return &SignatureVerifier{
+		Keys:    keys,
+		MaxSkew: defaultSignatureSkew,
+	}
+}
+
+func (v *SignatureVerifier) Verify(req *http.Request) error {
+	header := req.Header.Get(headerSignature)
+	if header == "" {
+		return ErrMissingSignature
+	}
+
+	params := parseSignatureHeader(header)
+	key, ok := v.Keys[params["keyId"]]
+	if !ok {
+		return fmt.Errorf("key id %q. %w", params["keyId"], ErrUnknownKeyId)
+	}
+
+	headers := strings.Fields(params["headers"])
+	for _, required := range signedHeaders {
+		if !containsString(headers, required) {
+			return fmt.Errorf("signature does not cover %q. %w", required, ErrInvalidSignature)
+		}
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return fmt.Errorf("error reading body for verification. error: %w", err)
+	}
+
+	if req.Header.Get(headerDigest) != digest(body) {
+		return ErrInvalidDigest
+	}
+
+	if v.MaxSkew > 0 {
+		date, err := http.ParseTime(req.Header.Get("Date"))
+		if err != nil {
+			return fmt.Errorf("invalid Date header. %w", ErrInvalidSignature)
+		}
+
+		if skew := time.Since(date); skew > v.MaxSkew || skew < -v.MaxSkew {
+			return fmt.Errorf("Date header is %s away from the current time. %w", skew, ErrInvalidSignature)
+		}
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("signature is not valid base64. %w", ErrInvalidSignature)
+	}
+
+	message := []byte(signingString(req, headers))
+	algorithm, err := signatureAlgorithm(key)
+	if err != nil {
+		return err
+	}
+
+	if params["algorithm"] != "" && params["algorithm"] != algorithm && params["algorithm"] != "hs2019" {
+		return fmt.Errorf("algorithm %q does not match key %q. %w", params["algorithm"], params["keyId"], ErrInvalidSignature)
+	}
+
+	switch algorithm {
+	case algorithmRSASHA256:
+		hashed := sha256.Sum256(message)
+		if rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, hashed[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+	case algorithmEd25519:
+		if !ed25519.Verify(key.(ed25519.PublicKey), message, signature) {
+			return ErrInvalidSignature
+		}
+	}
+
+	return nil
+}
+
+// Handler rejects requests with an invalid signature with a 401 before they reach next.
+func (v *SignatureVerifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func signatureAlgorithm(key crypto.PublicKey) (string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return algorithmRSASHA256, nil
+	case ed25519.PublicKey:
+		return algorithmEd25519, nil
+	}
+	return "", fmt.Errorf("unsupported signing key type %T. only rsa and ed25519 keys are supported", key)
+}
+
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, len(headers))
+	for i, header := range headers {
+		switch header {
+		case "(request-target)":
+			lines[i] = fmt.Sprintf("(request-target): %s %s", strings.ToLower(req.Method), req.URL.RequestURI())
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			lines[i] = "host: " + host
+		default:
+			lines[i] = fmt.Sprintf("%s: %s", header, req.Header.Get(header))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// readBody returns the request body, leaving it in place to be read again.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func parseSignatureHeader(header string) map[string]string {
+	params := map[string]string{}
+	for _, param := range strings.Split(header, ",") {
+		if i := strings.Index(param, "="); i > 0 {
+			params[strings.TrimSpace(param[:i])] = strings.Trim(strings.TrimSpace(param[i+1:]), `"`)
+		}
+	}
+	return params
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSignedRequests(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	keys := []struct {
+		scenario  string
+		key       crypto.Signer
+		algorithm string
+	}{
+		{"RSA", rsaKey, algorithmRSASHA256},
+		{"Ed25519", edKey, algorithmEd25519},
+	}
+
+	for _, k := range keys {
+		t.Run(k.scenario, func(t *testing.T) {
+			verifier := NewSignatureVerifier(map[string]crypto.PublicKey{"f3-key": k.key.Public()})
+			server := httptest.NewServer(verifier.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.Contains(r.Header.Get(headerSignature), `algorithm="`+k.algorithm+`"`) {
+					t.Errorf("unexpected signature header %q", r.Header.Get(headerSignature))
+				}
+				body, _ := ioutil.ReadAll(r.Body)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write(body)
+			})))
+			defer server.Close()
+
+			signer, err := NewRequestSigner("f3-key", k.key)
+			if err != nil {
+				t.Fatalf("failed to create signer %q", err)
+			}
+
+			client := testClient(server, 0)
+			client.Signer = signer
+			_, err = client.Create().
+				WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+				WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+				WithCountry(Countries["GB"]).
+				WithBankId("000006").
+				WithBic("NWBKGB22").
+				WithBankIdCode("GBDSC").
+				WithAccountClassification("Personal").
+				Do(context.Background())
+			if err != nil {
+				t.Errorf("signed create request failed with %q", err)
+			}
+
+			_, err = client.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+			if err != nil && errors.Is(err, F3StatusUnauthorized) {
+				t.Errorf("signed fetch request was rejected %q", err)
+			}
+		})
+	}
+}
+
+func TestSignatureVerificationFailures(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	signer, _ := NewRequestSigner("f3-key", key)
+	verifier := NewSignatureVerifier(map[string]crypto.PublicKey{"f3-key": key.Public(), "other": other.Public()})
+
+	signed := func() *http.Request {
+		req, _ := http.NewRequest("POST", "http://localhost:8080/v1/organisation/accounts", bytes.NewReader([]byte(`{"data":{}}`)))
+		if err := signer.Sign(req); err != nil {
+			t.Fatalf("signing failed with %q", err)
+		}
+		return req
+	}
+
+	if err := verifier.Verify(signed()); err != nil {
+		t.Errorf("valid signature failed verification with %q", err)
+	}
+
+	failures := []struct {
+		scenario string
+		tamper   func(req *http.Request)
+		expected error
+	}{
+		{"Missing Signature", func(req *http.Request) { req.Header.Del(headerSignature) }, ErrMissingSignature},
+		{"Tampered Body", func(req *http.Request) {
+			req.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"data":{"id":"x"}}`)))
+		}, ErrInvalidDigest},
+		{"Tampered Target", func(req *http.Request) { req.URL.Path = "/v1/organisation/accounts/x" }, ErrInvalidSignature},
+		{"Unknown Key Id", func(req *http.Request) {
+			req.Header.Set(headerSignature, strings.Replace(req.Header.Get(headerSignature), "f3-key", "missing", 1))
+		}, ErrUnknownKeyId},
+		{"Wrong Key", func(req *http.Request) {
+			req.Header.Set(headerSignature, strings.Replace(req.Header.Get(headerSignature), "f3-key", "other", 1))
+		}, ErrInvalidSignature},
+		{"Stale Date", func(req *http.Request) { req.Header.Set("Date", "Wed, 21 Oct 2015 07:28:00 GMT") }, ErrInvalidSignature},
+	}
+
+	for _, f := range failures {
+		t.Run(f.scenario, func(t *testing.T) {
+			req := signed()
+			f.tamper(req)
+			if err := verifier.Verify(req); !errors.Is(err, f.expected) {
+				t.Errorf("expected %q but got %v", f.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "f3signing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	path := filepath.Join(dir, "key.pem")
+	_ = ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	loaded, err := LoadSigningKey(path)
+	if err != nil {
+		t.Fatalf("failed to load signing key %q", err)
+	}
+
+	if !key.Equal(loaded) {
+		t.Errorf("loaded key does not match the generated key")
+	}
+}
@@ -0,0 +1,106 @@
+package form3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+// TLSSettings describes how the client verifies the api and, for mutual TLS, authenticates itself. Files and
+// in-memory values can be mixed, certificates loaded from files are appended to the in-memory ones.
+type TLSSettings struct {
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyFile  string
+	RootCAs        *x509.CertPool
+	Certificates   []tls.Certificate
+	MinVersion     uint16
+}
+
+func NewTLSConfig(settings TLSSettings) (*tls.Config, error) {
+	config := &tls.Config{
+		RootCAs:      settings.RootCAs,
+		Certificates: settings.Certificates,
+		MinVersion:   settings.MinVersion,
+	}
+
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if settings.CACertFile != "" {
+		pem, err := ioutil.ReadFile(settings.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate %q. error: %w", settings.CACertFile, err)
+		}
+
+		if config.RootCAs == nil {
+			config.RootCAs = x509.NewCertPool()
+		}
+
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid PEM certificates found in CA certificate %q", settings.CACertFile)
+		}
+	}
+
+	if settings.ClientCertFile != "" || settings.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.ClientCertFile, settings.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate %q and key %q. error: %w",
+				settings.ClientCertFile, settings.ClientKeyFile, err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	return config, nil
+}
+
+// tlsTransport clones the transport with config applied, a nil transport being the default transport.
+func tlsTransport(roundTripper http.RoundTripper, config *tls.Config) (*http.Transport, error) {
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+
+	transport, ok := roundTripper.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("cannot apply tls config to a %T transport. configure tls on the transport itself",
+			roundTripper)
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+func tlsSettingsFromEnv() (settings TLSSettings, ok bool, err error) {
+	settings.CACertFile = os.Getenv(F3CACert)
+	settings.ClientCertFile = os.Getenv(F3ClientCert)
+	settings.ClientKeyFile = os.Getenv(F3ClientKey)
+
+	if version := os.Getenv(F3TLSMinVersion); version != "" {
+		if settings.MinVersion, err = parseTLSVersion(version); err != nil {
+			return settings, false, err
+		}
+	}
+
+	ok = settings.CACertFile != "" || settings.ClientCertFile != "" || settings.ClientKeyFile != "" ||
+		settings.MinVersion != 0
+	return settings, ok, nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported tls version %q. supported versions are '1.0', '1.1', '1.2' and '1.3'", version)
+}
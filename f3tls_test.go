@@ -0,0 +1,205 @@
+package form3
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResourceURL(t *testing.T) {
+	urls := []struct {
+		scenario string
+		baseURL  string
+		resource string
+		link     string
+	}{
+		{"Host Only", "localhost:8080",
+			"http://localhost:8080/v1/organisation/accounts",
+			"http://localhost:8080/v1/organisation/accounts?page%5Bnumber%5D=1"},
+		{"Https Scheme", "https://api.form3.tech",
+			"https://api.form3.tech/v1/organisation/accounts",
+			"https://api.form3.tech/v1/organisation/accounts?page%5Bnumber%5D=1"},
+		{"Path Prefix", "https://api.form3.tech/gateway/",
+			"https://api.form3.tech/gateway/v1/organisation/accounts",
+			"https://api.form3.tech/gateway/v1/organisation/accounts?page%5Bnumber%5D=1"},
+	}
+
+	for _, u := range urls {
+		t.Run(u.scenario, func(t *testing.T) {
+			client := SetupF3Client(F3Env{F3BaseURL: u.baseURL})
+
+			if resource := client.resourceURL("/v1/organisation/accounts"); resource != u.resource {
+				t.Errorf("expected resource url %q but got %q", u.resource, resource)
+			}
+
+			links := []string{
+				"/v1/organisation/accounts?page%5Bnumber%5D=1",
+				"http://internal.form3.tech/v1/organisation/accounts?page%5Bnumber%5D=1",
+			}
+			for _, link := range links {
+				if resolved := client.linkURL(link); resolved != u.link {
+					t.Errorf("expected link %q to resolve to %q but got %q", link, u.link, resolved)
+				}
+			}
+		})
+	}
+}
+
+func TestTLSCustomRootCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"id":"81d62ace-23f2-4aff-a7d6-60d7674bc5bb"}}`))
+	}))
+	defer server.Close()
+
+	client := SetupF3Client(F3Env{F3BaseURL: server.URL})
+	fetch := client.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb")
+	if _, err := fetch.Do(context.Background()); err == nil {
+		t.Errorf("expected certificate signed by an unknown authority to be rejected")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	config, err := NewTLSConfig(TLSSettings{RootCAs: pool})
+	if err != nil {
+		t.Fatalf("failed to create tls config %q", err)
+	}
+
+	client = tlsClient(t, server.URL, config)
+	if _, err := client.Fetch().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background()); err != nil {
+		t.Errorf("fetch over tls failed with %q", err)
+	}
+}
+
+func tlsClient(t *testing.T, baseURL string, config *tls.Config) *F3Client {
+	client, err := New(WithBaseURL(baseURL), WithMaxRetries(0), WithTLS(config))
+	if err != nil {
+		t.Fatalf("failed to create client %q", err)
+	}
+	return client
+}
+
+type wrappingTransport struct {
+	http.RoundTripper
+}
+
+func TestTLSRequiresAnHTTPTransport(t *testing.T) {
+	config, _ := NewTLSConfig(TLSSettings{})
+	transport := wrappingTransport{http.DefaultTransport}
+
+	if _, err := New(WithBaseURL("https://localhost"), WithTransport(transport), WithTLS(config)); err == nil {
+		t.Errorf("expected tls with a custom transport to be rejected")
+	}
+
+	client, err := New(WithBaseURL("https://localhost"), WithTransport(&http.Transport{}), WithTLS(config))
+	if err != nil {
+		t.Fatalf("failed to create client %q", err)
+	}
+	if transport, ok := client.HTTPClient.Transport.(*http.Transport); !ok || transport.TLSClientConfig != config {
+		t.Errorf("expected the tls config to be applied to the transport")
+	}
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	config, _ := NewTLSConfig(TLSSettings{RootCAs: pool, MinVersion: tls.VersionTLS13})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	if err := tlsClient(t, server.URL, config).request(req, nil); err == nil {
+		t.Errorf("expected the handshake to fail with a server limited to tls 1.2")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "f3tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, clientCert := writeClientCertificate(t, dir)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "f3client" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	config, _ := NewTLSConfig(TLSSettings{CACertFile: caFile})
+	if err := tlsClient(t, server.URL, config).request(req, nil); err == nil {
+		t.Errorf("expected the server to reject a client without a certificate")
+	}
+
+	config, err = NewTLSConfig(TLSSettings{CACertFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("failed to load tls config from files %q", err)
+	}
+
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	if err := tlsClient(t, server.URL, config).request(req, nil); err != nil {
+		t.Errorf("mutual tls request failed with %q", err)
+	}
+}
+
+func writeClientCertificate(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "f3client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	_ = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	_ = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile, cert
+}
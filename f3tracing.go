@@ -0,0 +1,219 @@
+package form3
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const headerTraceParent = "traceparent"
+
+// Tracer starts spans for api calls and the http attempts made by them. The parent of a span, if any, is
+// found with SpanFromContext.
+type Tracer interface {
+	Start(ctx context.Context, name string) Span
+}
+
+type Span interface {
+	SpanContext() SpanContext
+	SetAttributes(attributes ...Field)
+	RecordError(err error)
+	End()
+}
+
+// SpanContext identifies a span following the W3C trace context specification.
+type SpanContext struct {
+	TraceId [16]byte
+	SpanId  [8]byte
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceId != [16]byte{} && sc.SpanId != [8]byte{}
+}
+
+// TraceParent formats the span context as a W3C traceparent header value.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceId[:]), hex.EncodeToString(sc.SpanId[:]), flags)
+}
+
+func ParseTraceParent(traceParent string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(traceParent, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("invalid traceparent %q", traceParent)
+	}
+
+	if _, err := hex.Decode(sc.TraceId[:], []byte(parts[1])); err != nil {
+		return sc, fmt.Errorf("invalid trace id in traceparent %q", traceParent)
+	}
+
+	if _, err := hex.Decode(sc.SpanId[:], []byte(parts[2])); err != nil {
+		return sc, fmt.Errorf("invalid span id in traceparent %q", traceParent)
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, fmt.Errorf("invalid flags in traceparent %q", traceParent)
+	}
+	sc.Sampled = flags[0]&1 == 1
+
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("invalid traceparent %q", traceParent)
+	}
+	return sc, nil
+}
+
+type spanKey struct{}
+
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span stored in the context, or nil when there is none.
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+func WithTracer(tracer Tracer) Option {
+	return func(o *clientOptions) error {
+		o.tracer = tracer
+		return nil
+	}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SpanContext() SpanContext          { return SpanContext{} }
+func (nopSpan) SetAttributes(attributes ...Field) {}
+func (nopSpan) RecordError(err error)             {}
+func (nopSpan) End()                              {}
+
+// startSpan starts a span as a child of the span in ctx. It is safe to call on a nil client or without a
+// Tracer, in which case the span does nothing.
+func (c *F3Client) startSpan(ctx context.Context, name string, attributes ...Field) (context.Context, Span) {
+	if c == nil || c.Tracer == nil {
+		return ctx, nopSpan{}
+	}
+
+	span := c.Tracer.Start(ctx, name)
+	span.SetAttributes(attributes...)
+	return ContextWithSpan(ctx, span), span
+}
+
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+func setValidationOutcome(span Span, errs []error) {
+	if len(errs) > 0 {
+		span.SetAttributes(F("f3.validation", "failed"), F("f3.validation_errors", len(errs)))
+		return
+	}
+	span.SetAttributes(F("f3.validation", "passed"))
+}
+
+// RecordingTracer keeps every span in memory, it is intended for tests.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+func (t *RecordingTracer) Start(ctx context.Context, name string) Span {
+	span := &RecordedSpan{
+		Name:       name,
+		Attributes: map[string]interface{}{},
+		StartTime:  time.Now(),
+		tracer:     t,
+	}
+
+	if parent := SpanFromContext(ctx); parent != nil && parent.SpanContext().IsValid() {
+		span.Parent = parent.SpanContext()
+		span.context.TraceId = span.Parent.TraceId
+	} else {
+		_, _ = rand.Read(span.context.TraceId[:])
+	}
+	_, _ = rand.Read(span.context.SpanId[:])
+	span.context.Sampled = true
+	return span
+}
+
+// Spans returns the spans which have ended, in the order they ended.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+type RecordedSpan struct {
+	Name       string
+	Parent     SpanContext
+	Attributes map[string]interface{}
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+
+	mu      sync.Mutex
+	context SpanContext
+	tracer  *RecordingTracer
+}
+
+func (s *RecordedSpan) SpanContext() SpanContext {
+	return s.context
+}
+
+func (s *RecordedSpan) SetAttributes(attributes ...Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attribute := range attributes {
+		s.Attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (s *RecordedSpan) Attribute(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Attributes[key]
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Errors = append(s.Errors, err)
+}
+
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	if !s.EndTime.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.spans = append(s.tracer.spans, s)
+}
@@ -0,0 +1,139 @@
+package form3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTracingSpans(t *testing.T) {
+	var calls int32
+	var mu sync.Mutex
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceParents = append(traceParents, r.Header.Get("traceparent"))
+		mu.Unlock()
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	tracer := NewRecordingTracer()
+	client := testClient(server, 1)
+	client.Tracer = tracer
+
+	parent := tracer.Start(context.Background(), "caller")
+	_, err := client.Create().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithOrganisationId("ea68b98a-471a-4c71-ac83-0f96a2bee973").
+		WithCountry(Countries["GB"]).
+		WithBankId("000006").
+		WithBic("NWBKGB22").
+		WithBankIdCode("GBDSC").
+		WithAccountClassification("Personal").
+		Do(ContextWithSpan(context.Background(), parent))
+	if err != nil {
+		t.Fatalf("create failed with %q", err)
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 2 attempt spans and a create span but got %d spans", len(spans))
+	}
+
+	first, second, create := spans[0], spans[1], spans[2]
+	if create.Name != "form3.create" || create.Parent != parent.SpanContext() {
+		t.Errorf("expected form3.create as a child of the caller span but got %q with parent %v", create.Name, create.Parent)
+	}
+
+	expected := map[string]interface{}{
+		"f3.operation":       OperationCreate,
+		"f3.account_id":      "81d62ace-23f2-4aff-a7d6-60d7674bc5bb",
+		"f3.organisation_id": "ea68b98a-471a-4c71-ac83-0f96a2bee973",
+		"f3.validation":      "passed",
+		"http.status_code":   http.StatusCreated,
+		"f3.retry_attempt":   1,
+	}
+	for key, value := range expected {
+		if create.Attribute(key) != value {
+			t.Errorf("expected create span attribute %s=%v but got %v", key, value, create.Attribute(key))
+		}
+	}
+
+	for i, attempt := range []*RecordedSpan{first, second} {
+		if attempt.Name != "form3.http" || attempt.Parent != create.SpanContext() || attempt.Attribute("f3.retry_attempt") != i {
+			t.Errorf("unexpected attempt span %q parent %v attributes %v", attempt.Name, attempt.Parent, attempt.Attributes)
+		}
+
+		if traceParents[i] != attempt.SpanContext().TraceParent() {
+			t.Errorf("expected traceparent %q but got %q", attempt.SpanContext().TraceParent(), traceParents[i])
+		}
+	}
+
+	if first.Attribute("http.status_code") != http.StatusServiceUnavailable || len(first.Errors) != 1 {
+		t.Errorf("expected the first attempt to record the 503 but got %v %v", first.Attributes, first.Errors)
+	}
+}
+
+func TestTracingValidationAndPagination(t *testing.T) {
+	server := pagedServer(2, -1)
+	defer server.Close()
+
+	tracer := NewRecordingTracer()
+	client := testClient(server, 0)
+	client.Tracer = tracer
+
+	_, _ = client.Fetch().Do(context.Background())
+	spans := tracer.Spans()
+	if len(spans) != 1 || spans[0].Attribute("f3.validation") != "failed" || len(spans[0].Errors) != 1 {
+		t.Fatalf("expected a single failed fetch span but got %v", spans)
+	}
+
+	tracer.Reset()
+	_, paginator, err := client.List().WithPageSize(2).Do(context.Background())
+	if err != nil {
+		t.Fatalf("list failed with %q", err)
+	}
+
+	if _, _, err := paginator.NextPage(context.Background()); err != nil {
+		t.Fatalf("next page failed with %q", err)
+	}
+
+	var next *RecordedSpan
+	for _, span := range tracer.Spans() {
+		if span.Name == "form3.list.next" {
+			next = span
+		}
+	}
+
+	if next == nil || next.Attribute("f3.page_number") != 1 || next.Attribute("f3.operation") != OperationList {
+		t.Errorf("expected a form3.list.next span for page 1 but got %+v", next)
+	}
+}
+
+func TestTraceParent(t *testing.T) {
+	sc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("failed to parse traceparent %q", err)
+	}
+
+	if !sc.Sampled || sc.TraceParent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("traceparent did not round trip %q", sc.TraceParent())
+	}
+
+	for _, invalid := range []string{"", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01"} {
+		if _, err := ParseTraceParent(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
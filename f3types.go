@@ -75,17 +75,9 @@ type IBAN string
 var ibanRegex string = "^[A-Z]{2}[0-9]{2}[A-Z0-9]{0,64}$"
 var zeroValueIBAN = IBAN("")
 
+// IsValid checks the IBAN against ISO 13616, failures are reported as an *InvalidIBAN naming the rule broken.
 func (i *IBAN) IsValid() error {
-	if l := len(*i); l < 16 || l > 34 {
-		return fmt.Errorf("invalid IBAN %q. Length: %d - min minLength 16 characters", *i, len(*i))
-	}
-
-	match, _ := regexp.MatchString(ibanRegex, string(*i))
-	if !match {
-		return fmt.Errorf("invalid iban %q, iban should match %q", *i, ibanRegex)
-	}
-
-	return nil
+	return validateIban(*i)
 }
 
 func (i *IBAN) IsZeroValue() bool {
@@ -0,0 +1,222 @@
+package form3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type updateBuilder struct {
+	client     *F3Client
+	AccountId  UUID
+	Version    int
+	Type       string
+	Attributes AccountPatchAttributes
+}
+
+// AccountPatchAttributes holds the attributes the api allows to change on an existing account. Only the
+// attributes which have been set are sent in the PATCH request.
+type AccountPatchAttributes struct {
+	Name                    []Identifier    `json:"name,omitempty"`
+	AlternativeNames        []Identifier    `json:"alternative_names,omitempty"`
+	AccountClassification   *Classification `json:"account_classification,omitempty"`
+	JointAccount            *bool           `json:"joint_account,omitempty"`
+	AccountMatchingOptOut   *bool           `json:"account_matching_opt_out,omitempty"`
+	SecondaryIdentification *Identifier     `json:"secondary_identification,omitempty"`
+	Switched                *bool           `json:"switched,omitempty"`
+	Status                  *Status         `json:"status,omitempty"`
+	CustomerId              *string         `json:"customer_id,omitempty"`
+}
+
+func (a AccountPatchAttributes) isEmpty() bool {
+	return len(a.Name) == 0 && len(a.AlternativeNames) == 0 && a.AccountClassification == nil &&
+		a.JointAccount == nil && a.AccountMatchingOptOut == nil && a.SecondaryIdentification == nil &&
+		a.Switched == nil && a.Status == nil && a.CustomerId == nil
+}
+
+type patchPayload struct {
+	Data patchData `json:"data"`
+}
+
+type patchData struct {
+	Id         UUID                   `json:"id"`
+	RecordType string                 `json:"type"`
+	Version    int                    `json:"version"`
+	Attributes AccountPatchAttributes `json:"attributes"`
+}
+
+type UpdateBuilder interface {
+	WithAccountId(accountId UUID) UpdateBuilder
+	WithVersion(version int) UpdateBuilder
+	WithName(name Identifier) UpdateBuilder
+	WithAlternativeNames(alternativeName Identifier) UpdateBuilder
+	WithAccountClassification(classification Classification) UpdateBuilder
+	WithJointAccount(jointAccount bool) UpdateBuilder
+	WithAccountMatchingOptOut(optOut bool) UpdateBuilder
+	WithSecondaryIdentification(identifier Identifier) UpdateBuilder
+	WithSwitched(switched bool) UpdateBuilder
+	WithStatus(status Status) UpdateBuilder
+	WithCustomerId(customerId string) UpdateBuilder
+	UnsafeRequest(ctx context.Context, response chan<- *Payload, errors chan<- []error) UpdateBuilder
+	Request(ctx context.Context, response chan<- *Payload, errors chan<- []error) UpdateBuilder
+	Validate(errors chan<- []error) UpdateBuilder
+	UnsafeDo(ctx context.Context) (*Payload, error)
+	Do(ctx context.Context) (*Payload, error)
+}
+
+func newUpdateBuilder(client *F3Client) UpdateBuilder {
+	return updateBuilder{
+		client: client,
+		Type:   ACCOUNTS,
+	}
+}
+
+func (u updateBuilder) UnsafeRequest(ctx context.Context, response chan<- *Payload, errors chan<- []error) UpdateBuilder {
+	go func() {
+		payload, err := u.UnsafeDo(ctx)
+		logPayload(payload, err, response, errors)
+	}()
+	return u
+}
+
+func (u updateBuilder) Request(ctx context.Context, response chan<- *Payload, errors chan<- []error) UpdateBuilder {
+	go func() {
+		payload, err := u.Do(ctx)
+		logPayload(payload, err, response, errors)
+	}()
+	return u
+}
+
+func (u updateBuilder) Validate(errors chan<- []error) UpdateBuilder {
+	if err := u.validate(); len(err) > 0 {
+		errors <- err
+	}
+	close(errors)
+	return u
+}
+
+func (u updateBuilder) UnsafeDo(ctx context.Context) (payload *Payload, err error) {
+	ctx, span := u.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+	span.SetAttributes(F("f3.validation", "skipped"))
+
+	return u.internalRequest(ctx)
+}
+
+func (u updateBuilder) Do(ctx context.Context) (payload *Payload, err error) {
+	ctx, span := u.startSpan(ctx)
+	defer func() { endSpan(span, err) }()
+
+	errs := u.validate()
+	setValidationOutcome(span, errs)
+	if len(errs) > 0 {
+		return nil, ValidationErrors(errs)
+	}
+
+	return u.internalRequest(ctx)
+}
+
+func (u updateBuilder) startSpan(ctx context.Context) (context.Context, Span) {
+	return u.client.startSpan(ctx, "form3.update", F("f3.operation", OperationUpdate),
+		F("f3.account_id", string(u.AccountId)), F("f3.version", u.Version))
+}
+
+func (u updateBuilder) validate() (errors []error) {
+	if u.client == nil {
+		errors = append(errors, ruleError("client", fmt.Errorf("F3Client not set")))
+	}
+
+	errors = append(errors, patchValidators(u)...)
+	u.client.observeValidation(OperationUpdate, errors)
+	return errors
+}
+
+func (u updateBuilder) internalRequest(ctx context.Context) (*Payload, error) {
+	byteArray, err := json.Marshal(patchPayload{
+		Data: patchData{
+			Id:         u.AccountId,
+			RecordType: u.Type,
+			Version:    u.Version,
+			Attributes: u.Attributes,
+		},
+	})
+	if err != nil {
+		u.client.log().Log(LevelError, "error marshalling json payload for account update", F("error", err))
+		return nil, fmt.Errorf("error marshalling payload for account %q - error: %w", u.AccountId, err)
+	}
+
+	url := u.client.resourceURL(fmt.Sprintf("/v1/organisation/accounts/%s", url.PathEscape(string(u.AccountId))))
+	req, err := http.NewRequest("PATCH", url, bytes.NewReader(byteArray))
+	if err != nil {
+		u.client.log().Log(LevelError, "failed to create new http request", F("url", url), F("error", err))
+		return nil, fmt.Errorf("error creating request Method: 'PATCH' Url: %q - error: %w", redact(url), redactError(err))
+	}
+
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+	req = req.WithContext(withOperation(ctx, OperationUpdate))
+	res := &Payload{}
+	if err := u.client.request(req, res); err != nil {
+		u.client.log().Log(LevelError, "error requesting PATCH", F("url", url), F("error", err))
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (u updateBuilder) WithAccountId(accountId UUID) UpdateBuilder {
+	u.AccountId = accountId
+	return u
+}
+
+func (u updateBuilder) WithVersion(version int) UpdateBuilder {
+	u.Version = version
+	return u
+}
+
+func (u updateBuilder) WithName(name Identifier) UpdateBuilder {
+	u.Attributes.Name = append(u.Attributes.Name, name)
+	return u
+}
+
+func (u updateBuilder) WithAlternativeNames(name Identifier) UpdateBuilder {
+	u.Attributes.AlternativeNames = append(u.Attributes.AlternativeNames, name)
+	return u
+}
+
+func (u updateBuilder) WithAccountClassification(classification Classification) UpdateBuilder {
+	u.Attributes.AccountClassification = &classification
+	return u
+}
+
+func (u updateBuilder) WithJointAccount(jointAccount bool) UpdateBuilder {
+	u.Attributes.JointAccount = &jointAccount
+	return u
+}
+
+func (u updateBuilder) WithAccountMatchingOptOut(optOut bool) UpdateBuilder {
+	u.Attributes.AccountMatchingOptOut = &optOut
+	return u
+}
+
+func (u updateBuilder) WithSecondaryIdentification(identifier Identifier) UpdateBuilder {
+	u.Attributes.SecondaryIdentification = &identifier
+	return u
+}
+
+func (u updateBuilder) WithSwitched(switched bool) UpdateBuilder {
+	u.Attributes.Switched = &switched
+	return u
+}
+
+func (u updateBuilder) WithStatus(status Status) UpdateBuilder {
+	u.Attributes.Status = &status
+	return u
+}
+
+func (u updateBuilder) WithCustomerId(customerId string) UpdateBuilder {
+	u.Attributes.CustomerId = &customerId
+	return u
+}
@@ -0,0 +1,96 @@
+package form3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUpdateValidation(t *testing.T) {
+	updates := []struct {
+		scenario    string
+		builder     UpdateBuilder
+		expectError bool
+		expected    error
+	}{
+		{"Valid Update", SetupF3Client(F3Env{}).Update().
+			WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+			WithName("Shawn").
+			WithStatus(CONFIRMED), false, nil},
+		{"Missing Account Id", SetupF3Client(F3Env{}).Update().
+			WithName("Shawn"), true, accountIdFieldMissing},
+		{"Nothing To Update", SetupF3Client(F3Env{}).Update().
+			WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb"), true, nothingToUpdate},
+		{"Too Many Names", SetupF3Client(F3Env{}).Update().
+			WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+			WithName("1").WithName("2").WithName("3").WithName("4").WithName("5"), true, TooManyNames},
+		{"Invalid Status", SetupF3Client(F3Env{}).Update().
+			WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+			WithStatus("CORRUPTED"), true, nil},
+	}
+
+	for _, u := range updates {
+		t.Run(u.scenario, func(t *testing.T) {
+			errs := make(chan []error, 1)
+			u.builder.Validate(errs)
+			err := <-errs
+
+			switch u.expectError {
+			case true:
+				if len(err) == 0 {
+					t.Errorf("validation did not fail when it was expected too!")
+				}
+
+				if u.expected != nil && !containsError(err, u.expected) {
+					t.Errorf("expected %q but got %v", u.expected, err)
+				}
+			case false:
+				if len(err) > 0 {
+					t.Errorf("validation failed with %v on a valid update", err)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/v1/organisation/accounts/81d62ace-23f2-4aff-a7d6-60d7674bc5bb" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body map[string]map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		attributes := body["data"]["attributes"].(map[string]interface{})
+		if len(attributes) != 2 || attributes["switched"] != false || body["data"]["version"] != float64(2) {
+			t.Errorf("patch should only contain the attributes that were set, got %v", body)
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"id":"81d62ace-23f2-4aff-a7d6-60d7674bc5bb","version":3,"attributes":{"name":["Shawn"]}}}`))
+	}))
+	defer server.Close()
+
+	payload, err := testClient(server, 0).Update().
+		WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").
+		WithVersion(2).
+		WithName("Shawn").
+		WithSwitched(false).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("update failed with %q", err)
+	}
+
+	if payload.Data.Version != 3 || payload.Data.Attributes.Name[0] != "Shawn" {
+		t.Errorf("unexpected payload returned %+v", payload)
+	}
+
+	var validationErrors ValidationErrors
+	_, err = testClient(server, 0).Update().WithAccountId("81d62ace-23f2-4aff-a7d6-60d7674bc5bb").Do(context.Background())
+	if !errors.As(err, &validationErrors) {
+		t.Errorf("expected validation errors but got %v", err)
+	}
+}
@@ -14,8 +14,60 @@ var countryFieldMissing = missingFieldError("country")
 var bicFieldMissing = missingFieldError("bic")
 var classificationFieldMissing = missingFieldError("account_classification")
 var TooManyNames = errors.New("names array is restricted to a maximum string[4]")
+var nothingToUpdate = errors.New("UpdateBuilder requires at least one attribute to update")
 var TooManyAlternativeNames = errors.New("alternative names array is restricted to a maximum string[3]")
 
+// ValidationError names the rule which rejected a builder, it unwraps to the error reported by the rule.
+type ValidationError struct {
+	Rule string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func ruleError(rule string, err error) error {
+	return &ValidationError{Rule: rule, Err: err}
+}
+
+func validationRule(err error) string {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Rule
+	}
+	return "unknown"
+}
+
+// rule names a validator, the name labels the ValidationErrors of the validator and the validation failure
+// metrics, so it must not change when the validator is refactored.
+type rule struct {
+	name     string
+	validate Validator
+}
+
+var (
+	setFieldsRule      = rule{"setFields", validateSetFields}
+	requiredFieldsRule = rule{"requiredFields", validateRequiredFields}
+	bankIdRule         = rule{"bankId", bankIdValidator}
+	bicRule            = rule{"bic", bicValidator}
+	bankIdCodeRule     = rule{"bankIdCode", bankIdCodeValidator}
+	accountNumberRule  = rule{"accountNumber", accountNumberValidator}
+	emptyIbanRule      = rule{"emptyIban", emptyIbanValidator}
+	italyRule          = rule{"italy", italyValidator}
+	ukModulusRule      = rule{"ukModulus", ukModulusValidator}
+	abaRoutingRule     = rule{"abaRouting", abaRoutingValidator}
+	frenchRibKeyRule   = rule{"frenchRibKey", frenchRibKeyValidator}
+	spanishCccRule     = rule{"spanishCcc", spanishCccValidator}
+	italianCinRule     = rule{"italianCin", italianCinValidator}
+	belgianCheckRule   = rule{"belgianCheck", belgianCheckValidator}
+	portugueseNibRule  = rule{"portugueseNib", portugueseNibValidator}
+)
+
 func missingFieldError(field string) error {
 	return fmt.Errorf("%q field required inside of CreateBuilder", field)
 }
@@ -23,56 +75,108 @@ func missingFieldError(field string) error {
 func postValidators(ab createBuilder) []error {
 	switch ab.Country {
 	case "GB":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bicValidator,
-			bankIdCodeValidator, accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bicRule,
+			bankIdCodeRule, accountNumberRule, ukModulusRule)(ab)
 	case "AU":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdCodeValidator, bicValidator,
-			accountNumberValidator, emptyIbanValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdCodeRule, bicRule,
+			accountNumberRule, emptyIbanRule)(ab)
 	case "BE":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule, belgianCheckRule)(ab)
 	case "CA":
-		return composeValidators(validateSetFields, validateRequiredFields, bicValidator, bankIdCodeValidator,
-			accountNumberValidator, emptyIbanValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bicRule, bankIdCodeRule,
+			accountNumberRule, emptyIbanRule)(ab)
 	case "FR":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule, frenchRibKeyRule)(ab)
 	case "DE":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule)(ab)
 	case "GR":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule)(ab)
 	case "HK":
-		return composeValidators(validateSetFields, validateRequiredFields, bicValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bicRule, bankIdCodeRule,
+			accountNumberRule)(ab)
 	case "IT":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator, italyValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule, italyRule, italianCinRule)(ab)
 	case "LU":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule)(ab)
 	case "NL":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bicValidator,
-			bankIdCodeValidator, accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bicRule,
+			bankIdCodeRule, accountNumberRule)(ab)
 	case "PL":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule)(ab)
 	case "PT":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule, portugueseNibRule)(ab)
 	case "ES":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule, spanishCccRule)(ab)
 	case "CH":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bankIdCodeValidator,
-			accountNumberValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, bankIdCodeRule,
+			accountNumberRule)(ab)
 	case "US":
-		return composeValidators(validateSetFields, validateRequiredFields, bankIdValidator, bicValidator,
-			bankIdCodeValidator, accountNumberValidator, emptyIbanValidator)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule, bankIdRule, abaRoutingRule,
+			bicRule, bankIdCodeRule, accountNumberRule, emptyIbanRule)(ab)
 	default:
-		return composeValidators(validateSetFields, validateRequiredFields)(ab)
+		return composeValidators(setFieldsRule, requiredFieldsRule)(ab)
+	}
+}
+
+// patchValidators runs the attributes of an update through the same rules used on account creation. Only the
+// attributes set on the UpdateBuilder are validated.
+func patchValidators(ub updateBuilder) (errors []error) {
+	if ub.AccountId.IsZeroValue() {
+		errors = append(errors, ruleError("accountId", fmt.Errorf("missing account id in update request %w", accountIdFieldMissing)))
+	} else if err := ub.AccountId.IsValid(); err != nil {
+		errors = append(errors, ruleError("accountId", err))
+	}
+
+	if ub.Version < 0 {
+		errors = append(errors, ruleError("version", fmt.Errorf("version %d cannot be smaller then 0", ub.Version)))
+	}
+
+	if ub.Attributes.isEmpty() {
+		return append(errors, ruleError("attributes", nothingToUpdate))
+	}
+
+	ab := createBuilder{
+		AccountAttributes: AccountAttributes{
+			Name:             ub.Attributes.Name,
+			AlternativeNames: ub.Attributes.AlternativeNames,
+		},
+	}
+
+	if ub.Attributes.AccountClassification != nil {
+		ab.AccountClassification = *ub.Attributes.AccountClassification
+		if ab.AccountClassification.IsZeroValue() {
+			errors = append(errors, ruleError("accountClassification", classificationFieldMissing))
+		}
+	}
+
+	if ub.Attributes.SecondaryIdentification != nil {
+		ab.SecondaryIdentification = *ub.Attributes.SecondaryIdentification
 	}
+
+	if ub.Attributes.Status != nil {
+		ab.Status = *ub.Attributes.Status
+	}
+
+	return append(errors, composeValidators(setFieldsRule)(ab)...)
+}
+
+// rejectsIban reports whether the postValidators chain of the country requires the IBAN to be empty.
+func rejectsIban(country Country) bool {
+	for _, err := range postValidators(createBuilder{AccountAttributes: AccountAttributes{Country: country, Iban: "-"}}) {
+		if validationRule(err) == emptyIbanRule.name {
+			return true
+		}
+	}
+	return false
 }
 
 func emptyIbanValidator(ab createBuilder) (errors []error) {
@@ -82,11 +186,11 @@ func emptyIbanValidator(ab createBuilder) (errors []error) {
 	return errors
 }
 
-func composeValidators(validators ...Validator) Validator {
+func composeValidators(rules ...rule) Validator {
 	f := func(ab createBuilder) (errors []error) {
-		for _, fx := range validators {
-			if err := fx(ab); err != nil {
-				errors = append(errors, err...)
+		for _, r := range rules {
+			for _, err := range r.validate(ab) {
+				errors = append(errors, ruleError(r.name, err))
 			}
 		}
 		return errors
@@ -113,6 +217,10 @@ func validateSetFields(ab createBuilder) (errors []error) {
 
 	if err := ab.Iban.IsValid(); !ab.Iban.IsZeroValue() && err != nil {
 		errors = append(errors, err)
+	} else if !ab.Iban.IsZeroValue() && !ab.Country.IsZeroValue() {
+		if err := ibanCountryMatches(ab.Iban, ab.Country); err != nil {
+			errors = append(errors, err)
+		}
 	}
 
 	if err := ab.AccountClassification.IsValid(); !ab.AccountClassification.IsZeroValue() && err != nil {
@@ -355,13 +463,13 @@ var accountNumberLengthMap = map[Country]stringValidation{
 		maxLength: 10,
 		regex:     "^(?!0).{6,10}$",
 	},
-	Countries["BE"]: exactLengthValidation(false, 7),
+	Countries["BE"]: optionalCheckDigitsValidation(7),
 	Countries["CA"]: stringValidation{
 		required:  false,
 		minLength: 7,
 		maxLength: 12,
 	},
-	Countries["FR"]: exactLengthValidation(false, 10),
+	Countries["FR"]: frenchAccountValidation,
 	Countries["DE"]: exactLengthValidation(false, 7),
 	Countries["GR"]: exactLengthValidation(false, 16),
 	Countries["HK"]: stringValidation{
@@ -373,8 +481,8 @@ var accountNumberLengthMap = map[Country]stringValidation{
 	Countries["LU"]: exactLengthValidation(false, 13),
 	Countries["NL"]: exactLengthValidation(false, 10),
 	Countries["PL"]: exactLengthValidation(false, 16),
-	Countries["PT"]: exactLengthValidation(false, 11),
-	Countries["ES"]: exactLengthValidation(false, 10),
+	Countries["PT"]: optionalCheckDigitsValidation(11),
+	Countries["ES"]: leadingCheckDigitsValidation(10),
 	Countries["CH"]: exactLengthValidation(false, 12),
 	Countries["US"]: stringValidation{
 		required:  false,
@@ -32,14 +32,14 @@ Feature: Form 3 Organisation - Account Client
     | GR      | 0000007     |          | GRBIC      |               |      | Personal       |
     | HK      |             | NWBKGB22 | HKNCC      |               |      | Business       |
     | IT      | 0000000010  |          | ITNCC      |               |      | Personal       |
-    | IT      | 00000000011 |          | ITNCC      | 000000000012  |      | Business       |
+    | IT      | X0542811101 |          | ITNCC      | 000000123456  |      | Business       |
     | LU      | 003         |          | LULUX      |               |      | Personal       |
     | NL      |             | NWBKGB22 |            |               |      | Business       |
     | PL      | 00000008    |          | PLKNR      |               |      | Personal       |
     | PT      | 00000008    |          | PTNCC      |               |      | Business       |
     | ES      | 00000008    |          | ESNCC      |               |      | Personal       |
     | CH      | 00005       |          | CHBCC      |               |      | Business       |
-    | US      | 000000009   | NWBKGB22 | USABA      |               |      | Personal       |
+    | US      | 021000021   | NWBKGB22 | USABA      |               |      | Personal       |
 
   Scenario Outline: creating corrupted accounts and expecting failure
     Given an initiated Client
@@ -0,0 +1,457 @@
+// Package form3test provides an in-memory fake of the Form3 account api for tests, so that clients can be
+// exercised without the docker compose stack.
+package form3test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	accountsPath    = "/v1/organisation/accounts"
+	defaultPageSize = 100
+	contentType     = "application/vnd.api+json"
+)
+
+// filters maps the filter[...] query parameters supported by the list api onto account attributes.
+var filters = []string{"bank_id", "bank_id_code", "account_number", "iban", "customer_id", "country"}
+
+type Account struct {
+	Id             string                 `json:"id"`
+	OrganisationId string                 `json:"organisation_id"`
+	Type           string                 `json:"type"`
+	Version        int                    `json:"version"`
+	CreatedOn      time.Time              `json:"created_on"`
+	ModifiedOn     time.Time              `json:"modified_on"`
+	Attributes     map[string]interface{} `json:"attributes"`
+}
+
+type links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+type errorResponse struct {
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// Fault makes the server answer matching requests with an error instead of handling them. An empty Method
+// matches every method, and Times limits how many requests the fault applies to, 0 meaning every request. A fault
+// without a StatusCode only delays the request by its Latency and adds its Header, the request is then handled
+// normally.
+type Fault struct {
+	Method       string
+	StatusCode   int
+	ErrorMessage string
+	Header       http.Header
+	Latency      time.Duration
+	Times        int
+}
+
+// Server is a fake of /v1/organisation/accounts supporting create, fetch, list with pagination and filters,
+// update and delete with version checks.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	accounts map[string]*Account
+	order    []string
+	faults   []*Fault
+	latency  time.Duration
+	requests int
+}
+
+// NewServer starts a fake server, which should be closed with Close when the test ends.
+func NewServer() *Server {
+	s := &Server{accounts: map[string]*Account{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// InjectFault queues a fault, faults are matched in the order they were injected.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// SetLatency delays every response by the given duration.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// Reset removes every account, fault and latency and clears the request count.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts = map[string]*Account{}
+	s.order = nil
+	s.faults = nil
+	s.latency = 0
+	s.requests = 0
+}
+
+// Requests returns the number of requests received, including the ones answered by a fault.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Account returns a copy of a stored account.
+func (s *Server) Account(id string) (Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[id]
+	if !ok {
+		return Account{}, false
+	}
+	return copyAccount(account), true
+}
+
+// Accounts returns copies of every stored account in the order they were created.
+func (s *Server) Accounts() []Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accounts := make([]Account, len(s.order))
+	for i, id := range s.order {
+		accounts[i] = copyAccount(s.accounts[id])
+	}
+	return accounts
+}
+
+// copyAccount deep copies an account, so that the copy is not changed by later updates to the stored account.
+func copyAccount(account *Account) Account {
+	copied := *account
+	copied.Attributes, _ = copyValue(account.Attributes).(map[string]interface{})
+	return copied
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			copied[key] = copyValue(value)
+		}
+		return copied
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make([]interface{}, len(v))
+		for i, value := range v {
+			copied[i] = copyValue(value)
+		}
+		return copied
+	}
+	return value
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		latency += fault.Latency
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil {
+		for key, values := range fault.Header {
+			w.Header()[key] = values
+		}
+	}
+
+	if fault != nil && fault.StatusCode != 0 {
+		message := fault.ErrorMessage
+		if message == "" {
+			message = http.StatusText(fault.StatusCode)
+		}
+		writeError(w, fault.StatusCode, message)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, accountsPath), "/")
+	switch {
+	case !strings.HasPrefix(r.URL.Path, accountsPath) || strings.Contains(id, "/"):
+		writeError(w, http.StatusNotFound, fmt.Sprintf("path %q not found", r.URL.Path))
+	case id == "" && r.Method == http.MethodPost:
+		s.create(w, r)
+	case id == "" && r.Method == http.MethodGet:
+		s.list(w, r)
+	case id == "":
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	case !validUUID(id):
+		writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+	case r.Method == http.MethodGet:
+		s.fetch(w, id)
+	case r.Method == http.MethodPatch:
+		s.update(w, r, id)
+	case r.Method == http.MethodDelete:
+		s.delete(w, r, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, fault := range s.faults {
+		if fault.Method != "" && !strings.EqualFold(fault.Method, r.Method) {
+			continue
+		}
+
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Data Account `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid json body: %s", err))
+		return
+	}
+
+	account := req.Data
+	switch {
+	case !validUUID(account.Id):
+		writeError(w, http.StatusBadRequest, "validation failure: id in body must be of type uuid")
+		return
+	case !validUUID(account.OrganisationId):
+		writeError(w, http.StatusBadRequest, "validation failure: organisation_id in body must be of type uuid")
+		return
+	case account.Type != "accounts":
+		writeError(w, http.StatusBadRequest, "validation failure: type in body should be one of [accounts]")
+		return
+	case account.Attributes["country"] == nil:
+		writeError(w, http.StatusBadRequest, "validation failure: country in body is required")
+		return
+	}
+
+	if _, ok := s.accounts[account.Id]; ok {
+		writeError(w, http.StatusConflict, "Account cannot be created as it violates a duplicate constraint")
+		return
+	}
+
+	now := time.Now().UTC()
+	account.Version = 0
+	account.CreatedOn = now
+	account.ModifiedOn = now
+	s.accounts[account.Id] = &account
+	s.order = append(s.order, account.Id)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data":  account,
+		"links": links{Self: accountsPath + "/" + account.Id},
+	})
+}
+
+func (s *Server) fetch(w http.ResponseWriter, id string) {
+	account, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  account,
+		"links": links{Self: accountsPath + "/" + id},
+	})
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, err := queryInt(query, "page[number]", 0)
+	if err != nil || page < 0 {
+		writeError(w, http.StatusBadRequest, "invalid page[number]")
+		return
+	}
+
+	size, err := queryInt(query, "page[size]", defaultPageSize)
+	if err != nil || size < 1 {
+		writeError(w, http.StatusBadRequest, "invalid page[size]")
+		return
+	}
+
+	matched := []*Account{}
+	for _, id := range s.order {
+		if account := s.accounts[id]; matches(account, query) {
+			matched = append(matched, account)
+		}
+	}
+
+	lastPage := 0
+	if len(matched) > 0 {
+		lastPage = (len(matched) - 1) / size
+	}
+
+	start, end := page*size, (page+1)*size
+	if start > len(matched) {
+		start = len(matched)
+	}
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	pageLinks := links{
+		Self:  pageLink(query, page, size),
+		First: pageLink(query, 0, size),
+		Last:  pageLink(query, lastPage, size),
+	}
+	if page < lastPage {
+		pageLinks.Next = pageLink(query, page+1, size)
+	}
+	if page > 0 {
+		pageLinks.Prev = pageLink(query, page-1, size)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  matched[start:end],
+		"links": pageLinks,
+	})
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, id string) {
+	account, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	var req struct {
+		Data Account `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid json body: %s", err))
+		return
+	}
+
+	if req.Data.Version != account.Version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	for key, value := range req.Data.Attributes {
+		account.Attributes[key] = value
+	}
+	account.Version++
+	account.ModifiedOn = time.Now().UTC()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  account,
+		"links": links{Self: accountsPath + "/" + id},
+	})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, id string) {
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	}
+
+	account, ok := s.accounts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	if version != account.Version {
+		writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	delete(s.accounts, id)
+	for i, ordered := range s.order {
+		if ordered == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func matches(account *Account, query url.Values) bool {
+	for _, filter := range filters {
+		value := query.Get(fmt.Sprintf("filter[%s]", filter))
+		if value != "" && fmt.Sprint(account.Attributes[filter]) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func pageLink(query url.Values, page, size int) string {
+	linkQuery := url.Values{}
+	for _, filter := range filters {
+		key := fmt.Sprintf("filter[%s]", filter)
+		if value := query.Get(key); value != "" {
+			linkQuery.Set(key, value)
+		}
+	}
+	linkQuery.Set("page[number]", strconv.Itoa(page))
+	linkQuery.Set("page[size]", strconv.Itoa(size))
+	return accountsPath + "?" + linkQuery.Encode()
+}
+
+func queryInt(query url.Values, key string, fallback int) (int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{ErrorMessage: message})
+}
+
+func validUUID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
@@ -0,0 +1,171 @@
+package form3test_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	form3 "github.com/shawnritchie/interview-accountapi-master"
+	"github.com/shawnritchie/interview-accountapi-master/form3test"
+)
+
+const organisationId = "ea68b98a-471a-4c71-ac83-0f96a2bee973"
+
+func newClient(t *testing.T, server *form3test.Server) *form3.F3Client {
+	client, err := form3.New(form3.WithBaseURL(server.URL), form3.WithMaxRetries(2),
+		form3.WithRetryWait(time.Millisecond, 5*time.Millisecond), form3.WithLogger(form3.NopLogger()))
+	if err != nil {
+		t.Fatalf("failed to create client %q", err)
+	}
+	return client
+}
+
+func create(client *form3.F3Client, id form3.UUID) (*form3.Payload, error) {
+	return client.Create().
+		WithAccountId(id).
+		WithOrganisationId(organisationId).
+		WithCountry(form3.Countries["GB"]).
+		WithBankId("400300").
+		WithBic("NWBKGB22").
+		WithBankIdCode("GBDSC").
+		WithAccountClassification("Personal").
+		Do(context.Background())
+}
+
+func accountId(i int) form3.UUID {
+	return form3.UUID(fmt.Sprintf("81d62ace-23f2-4aff-a7d6-60d7674bc5%02d", i))
+}
+
+func TestAccountLifecycle(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	created, err := create(client, accountId(1))
+	if err != nil {
+		t.Fatalf("create failed with %q", err)
+	}
+
+	if created.Data.Id != accountId(1) || created.Data.Attributes.BankId != "400300" || created.Data.Version != 0 {
+		t.Errorf("unexpected created account %+v", created.Data)
+	}
+
+	if _, err := create(client, accountId(1)); !errors.Is(err, form3.F3StatusConflict) {
+		t.Errorf("expected a duplicate account to conflict but got %v", err)
+	}
+
+	fetched, err := client.Fetch().WithAccountId(accountId(1)).Do(context.Background())
+	if err != nil || fetched.Data.OrganisationId != organisationId {
+		t.Errorf("expected to fetch the created account but got %+v, %v", fetched, err)
+	}
+
+	stored, _ := server.Account(string(accountId(1)))
+
+	updated, err := client.Update().WithAccountId(accountId(1)).WithVersion(0).WithCustomerId("c-1").Do(context.Background())
+	if err != nil || updated.Data.Version != 1 || updated.Data.Attributes.CustomerId != "c-1" {
+		t.Errorf("expected the update to bump the version but got %+v, %v", updated, err)
+	}
+
+	if stored.Attributes["customer_id"] == "c-1" || stored.Version != 0 {
+		t.Errorf("expected the copy returned by Account to be left untouched by the update, got %+v", stored)
+	}
+
+	if err := client.Delete().WithAccountId(accountId(1)).WithVersion(0).Do(context.Background()); !errors.Is(err, form3.F3StatusConflict) {
+		t.Errorf("expected deleting a stale version to conflict but got %v", err)
+	}
+
+	if err := client.Delete().WithAccountId(accountId(1)).WithVersion(1).Do(context.Background()); err != nil {
+		t.Errorf("delete failed with %q", err)
+	}
+
+	if _, err := client.Fetch().WithAccountId(accountId(1)).Do(context.Background()); !errors.Is(err, form3.F3StatusNotFound) {
+		t.Errorf("expected the deleted account to be gone but got %v", err)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	for i := 0; i < 5; i++ {
+		if _, err := create(client, accountId(i)); err != nil {
+			t.Fatalf("create failed with %q", err)
+		}
+	}
+
+	page, paginator, err := client.List().WithPageSize(2).Do(context.Background())
+	if err != nil || len(page.Data) != 2 || page.Links.Prev != "" || page.Links.Next == "" {
+		t.Fatalf("unexpected first page %+v, %v", page, err)
+	}
+
+	page, _, err = paginator.LastPage(context.Background())
+	if err != nil || len(page.Data) != 1 || page.Data[0].Id != accountId(4) || page.Links.Next != "" {
+		t.Errorf("unexpected last page %+v, %v", page, err)
+	}
+
+	it := client.List().WithPageSize(2).All(context.Background())
+	defer it.Close()
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if it.Err() != nil || count != 5 {
+		t.Errorf("expected to iterate 5 accounts but got %d, %v", count, it.Err())
+	}
+
+	filtered, _, err := client.List().WithBankIdFilter("400300").WithCountryFilter("GB").Do(context.Background())
+	if err != nil || len(filtered.Data) != 5 {
+		t.Errorf("expected the filters to match every account but got %+v, %v", filtered, err)
+	}
+
+	filtered, _, err = client.List().WithBankIdFilter("400301").Do(context.Background())
+	if err != nil || len(filtered.Data) != 0 {
+		t.Errorf("expected the filter to match no accounts but got %+v, %v", filtered, err)
+	}
+}
+
+func TestFaultsAndLatency(t *testing.T) {
+	server := form3test.NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	server.InjectFault(form3test.Fault{Method: http.MethodPost, StatusCode: http.StatusServiceUnavailable, Times: 2})
+	if _, err := create(client, accountId(1)); err != nil {
+		t.Fatalf("expected the create to succeed after retrying but got %q", err)
+	}
+
+	if server.Requests() != 3 {
+		t.Errorf("expected 2 faulted requests and 1 success but got %d requests", server.Requests())
+	}
+
+	server.InjectFault(form3test.Fault{StatusCode: http.StatusForbidden, ErrorMessage: "forbidden", Times: 1})
+	if _, err := client.Fetch().WithAccountId(accountId(1)).Do(context.Background()); !errors.Is(err, form3.F3StatusForbidden) {
+		t.Errorf("expected the injected forbidden error but got %v", err)
+	}
+
+	server.SetLatency(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Fetch().WithAccountId(accountId(1)).Do(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the latency to exceed the deadline but got %v", err)
+	}
+
+	server.SetLatency(0)
+	server.InjectFault(form3test.Fault{Latency: 20 * time.Millisecond, Times: 1})
+	start := time.Now()
+	if _, err := client.Fetch().WithAccountId(accountId(1)).Do(context.Background()); err != nil {
+		t.Errorf("expected a latency only fault to delay the fetch but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected the fetch to be delayed by the fault, took %s", elapsed)
+	}
+
+	server.Reset()
+	if len(server.Accounts()) != 0 || server.Requests() != 0 {
+		t.Errorf("expected reset to clear the server")
+	}
+}
@@ -1,2 +1,5 @@
-go test -v
+go test -v ./...
+if [ -f features/cassette.json ]; then
+  export F3Cassette=features/cassette.json
+fi
 godog
\ No newline at end of file
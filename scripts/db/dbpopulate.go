@@ -6,13 +6,16 @@ import (
 	"github.com/google/uuid"
 	"github.com/shawnritchie/interview-accountapi-master"
 	"os"
-	"time"
 )
 
 func init() {
 	if os.Getenv("F3BaseURL") == "" {
 		_ = os.Setenv("F3BaseURL", "localhost:8080")
 	}
+
+	if os.Getenv("F3RateLimit") == "" {
+		_ = os.Setenv("F3RateLimit", "100")
+	}
 }
 
 func main() {
@@ -37,6 +40,9 @@ func main() {
 			WithAccountId(form3.UUID(uuid.New().String())).
 			UnsafeRequest(context.Background(), response, errors)
 
-		time.Sleep(10 * time.Millisecond)
+		if err := <-errors; err != nil {
+			fmt.Printf("failed to populate account %d: %v\n", i, err)
+		}
+		<-response
 	}
 }
@@ -0,0 +1,58 @@
+// genmodulus bundles the Vocalink modulus weight and sort code substitution tables into the client, run it
+// through `go generate` after refreshing the files under data/.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func main() {
+	weights := flag.String("weights", "data/valacdos.txt", "vocalink modulus weight table")
+	substitutions := flag.String("substitutions", "data/scsubtab.txt", "vocalink sort code substitution table")
+	out := flag.String("o", "f3modulus_data.go", "generated go file")
+	flag.Parse()
+
+	if err := generate(*weights, *substitutions, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "genmodulus: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(weights, substitutions, out string) error {
+	w, err := ioutil.ReadFile(weights)
+	if err != nil {
+		return err
+	}
+
+	s, err := ioutil.ReadFile(substitutions)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by genmodulus from %s and %s. DO NOT EDIT.\n\n", weights, substitutions)
+	fmt.Fprintf(&buf, "package form3\n\n")
+	fmt.Fprintf(&buf, "const modulusWeightsData = %s\n\n", quote(w))
+	fmt.Fprintf(&buf, "const modulusSubstitutionsData = %s\n", quote(s))
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
+
+// quote prefers a raw string literal, which keeps the generated tables readable.
+func quote(data []byte) string {
+	s := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if strings.Contains(s, "`") {
+		return fmt.Sprintf("%q", s)
+	}
+	return "`" + s + "`"
+}